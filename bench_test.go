@@ -0,0 +1,121 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// These benchmarks cover the cost of creating and decorating errors, which is paid on every error
+// path, separately from the cost of rendering them, which is paid only when an error is inspected.
+// Run with -benchmem: allocations are most of the cost being measured.
+
+// nested calls fn from a stack depth representative of a real call site, since capture walks the
+// whole stack and a benchmark run directly from the test runner is unrealistically shallow.
+func nested(depth int, fn func()) {
+	if 0 == depth {
+		fn()
+		return
+	}
+	nested(depth-1, fn)
+}
+
+var sinkE *errors.E
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			sinkE = errors.New("benchmark")
+		}
+	})
+}
+
+func BenchmarkWrap(b *testing.B) {
+	cause := errors.New("cause")
+	b.ReportAllocs()
+	b.ResetTimer()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			sinkE = errors.Wrap(cause, "benchmark")
+		}
+	})
+}
+
+func BenchmarkWrapE(b *testing.B) {
+	cause := errors.New("cause")
+	annotation := errors.New("annotation")
+	b.ReportAllocs()
+	b.ResetTimer()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			sinkE = errors.WrapE(cause, annotation)
+		}
+	})
+}
+
+func BenchmarkTrace(b *testing.B) {
+	cause := errors.New("cause")
+	b.ReportAllocs()
+	b.ResetTimer()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			sinkE = errors.Trace(cause)
+		}
+	})
+}
+
+func BenchmarkTrack(b *testing.B) {
+	cause := errors.New("cause")
+	b.ReportAllocs()
+	b.ResetTimer()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			sinkE = errors.Track(cause)
+		}
+	})
+}
+
+// BenchmarkWrapLoop is the batch-validation shape: an error created and wrapped per item, most of
+// which are never rendered.
+func BenchmarkWrapLoop(b *testing.B) {
+	b.ReportAllocs()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			var err error
+			for j := 0; j < 10; j++ {
+				err = errors.Wrap(errors.New("invalid item"), "validating batch")
+			}
+			_ = err
+		}
+	})
+}
+
+// BenchmarkCreateAndRender pays for creation AND rendering, the worst case for deferring work.
+func BenchmarkCreateAndRender(b *testing.B) {
+	b.ReportAllocs()
+	nested(20, func() {
+		for i := 0; i < b.N; i++ {
+			_ = fmt.Sprintf("%+v", errors.Wrap(errors.New("cause"), "benchmark"))
+		}
+	})
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	err := errors.Wrap(errors.Wrap(errors.New("cause"), "middle"), "outer")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(err)
+	}
+}
+
+// BenchmarkHelper is the cost of a helper marking itself on every call, after the first.
+func BenchmarkHelper(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		errors.Helper()
+	}
+}
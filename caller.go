@@ -3,15 +3,38 @@ package errors
 import (
 	"fmt"
 	"runtime"
-	"strings"
+	"sync"
 
 	std_caller "github.com/bdlm/std/v2/caller"
 )
 
+// callerDepth is the number of program counters a caller stores inline. Deeper stacks spill into a
+// separate allocation, which is rare enough not to matter.
+const callerDepth = 32
+
 // caller is a github.com/bdlm/std.Caller interface implementation and holds
 // runtime.Caller data.
+//
+// Capture and symbolization are separate. NewCaller records the raw program counters of the stack,
+// which is a single runtime.Callers call, and nothing else: most errors are created, wrapped and
+// handled without anyone ever asking where they came from, and resolving file, line and function
+// for every frame up front was almost the entire cost of creating one. Resolution happens once, on
+// the first call to a method that needs it, and is safe for concurrent use.
 type caller struct {
+	stack [callerDepth]uintptr
+	pcs   []uintptr
+	skip  int
+
+	// panicking is set for a stack captured while a panic unwinds, see FromPanic. The trace then
+	// starts at the panic rather than at the deferred function that recovered it.
+	panicking bool
+
+	// next, if set, is the trace this caller's own frame is prepended to, see Trace.
+	next std_caller.Caller
+
+	once  sync.Once
 	file  string
+	fn    string
 	line  int
 	ok    bool
 	pc    uintptr
@@ -19,62 +42,222 @@ type caller struct {
 }
 
 // NewCaller returns a new Caller containing data for the current call stack.
+//
+// Frames belonging to this package, and to any package hidden with HidePackages or HideStdlib, are
+// left out, so the caller is the first frame outside them.
 func NewCaller() std_caller.Caller {
-	trace := std_caller.Trace{}
+	return newCaller(3)
+}
+
+// NewCallerSkip is NewCaller for a helper function. It leaves out an additional skip frames,
+// counted from the top of the stack after hidden frames have been left out, so NewCallerSkip(0) is
+// equivalent to NewCaller() and NewCallerSkip(1) reports the function that called the helper.
+//
+// The skipped frames are left out of the trace as well, so the trace always starts at the caller.
+// Frames of functions marked with Helper are left out after the skip is applied, so a helper that
+// marks itself does not also need to count itself.
+func NewCallerSkip(skip int) std_caller.Caller {
+	return newCallerSkip(skip)
+}
+
+// newCallerSkip captures the stack for an exported constructor that takes a skip count. Its own
+// frames and those of its caller belong to this package and are hidden, so no runtime skip beyond
+// newCaller's own is needed.
+func newCallerSkip(skip int) *caller {
+	clr := newCaller(4)
+	if 0 < skip {
+		clr.skip = skip
+	}
+	return clr
+}
+
+// newCaller captures the stack. skip is as for runtime.Callers, so it counts runtime.Callers itself
+// and newCaller as well as the frames of its callers.
+func newCaller(skip int) *caller {
 	clr := &caller{}
-	a := 0
-	for {
-		traceCaller := &caller{}
-		if traceCaller.pc, traceCaller.file, traceCaller.line, traceCaller.ok = runtime.Caller(a); traceCaller.ok {
-			if !strings.Contains(strings.ToLower(traceCaller.file), "github.com/bdlm/errors") ||
-				strings.HasSuffix(strings.ToLower(traceCaller.file), "_test.go") {
-				trace = append(trace, traceCaller)
-				if !clr.ok {
-					clr.pc = traceCaller.pc
-					clr.file = traceCaller.file
-					clr.line = traceCaller.line
-					clr.ok = traceCaller.ok
+	pcs := clr.stack[:]
+	n := runtime.Callers(skip, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(skip, pcs)
+	}
+	clr.pcs = pcs[:n]
+	return clr
+}
+
+// resolve symbolizes the captured program counters. It runs at most once per caller.
+func (caller *caller) resolve() {
+	caller.once.Do(func() {
+		trace := std_caller.Trace{}
+		frames := runtime.CallersFrames(caller.pcs)
+		atPanic := false
+		for {
+			frame, more := frames.Next()
+			switch {
+			case caller.panicking && "runtime.gopanic" == frame.Function:
+				// Everything above the panic is the recovery, not the failure.
+				trace = trace[:0]
+				atPanic = true
+			case atPanic && "runtime" == funcPackage(frame.Function):
+				// The runtime's own frames that raised the panic, for a runtime error such as a nil
+				// dereference. The frame that caused it is next.
+			default:
+				atPanic = false
+				if "" != frame.File && !Hidden(frame.Function, frame.File) {
+					trace = append(trace, &traceFrame{
+						file: frame.File,
+						fn:   frame.Function,
+						line: frame.Line,
+						pc:   frame.PC,
+					})
 				}
 			}
+			if !more {
+				break
+			}
+		}
+		if caller.skip < len(trace) {
+			trace = trace[caller.skip:]
 		} else {
-			break
+			trace = std_caller.Trace{}
 		}
-		a++
-	}
-	clr.trace = trace
-	return clr
+		for 0 < len(trace) && isHelper(trace[0].(*traceFrame).fn) {
+			trace = trace[1:]
+		}
+		if 0 < len(trace) {
+			top := trace[0].(*traceFrame)
+			caller.file = top.file
+			caller.fn = top.fn
+			caller.line = top.line
+			caller.ok = true
+			caller.pc = top.pc
+		}
+		if nil != caller.next {
+			if 1 < len(trace) {
+				trace = trace[:1]
+			}
+			trace = append(trace, caller.next.Trace()...)
+		}
+		caller.trace = trace
+	})
 }
 
 // File implements Caller.
 func (caller *caller) File() string {
+	caller.resolve()
 	return caller.file
 }
 
 // Func implements Caller.
 func (caller *caller) Func() string {
-	return runtime.FuncForPC(caller.pc).Name()
+	caller.resolve()
+	return caller.fn
 }
 
 // Line implements Caller.
 func (caller *caller) Line() int {
+	caller.resolve()
 	return caller.line
 }
 
 // Pc implements Caller.
+//
+// File, Line and Func describe the LOGICAL frame, so a call the compiler inlined is reported as the
+// function that was written rather than the one it was inlined into. Prefer them to resolving Pc
+// independently, which is only as accurate as the resolver's own handling of inlining.
 func (caller *caller) Pc() uintptr {
+	caller.resolve()
 	return caller.pc
 }
 
 // String implements fmt.Stringer.
 func (caller *caller) String() string {
+	caller.resolve()
 	return fmt.Sprintf(
 		"%s:%d",
-		runtime.FuncForPC(caller.pc).Name(),
+		caller.fn,
 		caller.line,
 	)
 }
 
 // Trace implements Caller.
 func (caller *caller) Trace() std_caller.Trace {
+	caller.resolve()
 	return caller.trace
 }
+
+// traceFrame is one resolved frame of a caller's trace.
+type traceFrame struct {
+	file string
+	fn   string
+	line int
+	pc   uintptr
+}
+
+// File implements Caller.
+func (frame *traceFrame) File() string {
+	return frame.file
+}
+
+// Func implements Caller.
+func (frame *traceFrame) Func() string {
+	return frame.fn
+}
+
+// Line implements Caller.
+func (frame *traceFrame) Line() int {
+	return frame.line
+}
+
+// Pc implements Caller.
+func (frame *traceFrame) Pc() uintptr {
+	return frame.pc
+}
+
+// String implements fmt.Stringer.
+func (frame *traceFrame) String() string {
+	return fmt.Sprintf("%s:%d", frame.fn, frame.line)
+}
+
+// Trace implements Caller. A frame of a trace has no trace of its own.
+func (frame *traceFrame) Trace() std_caller.Trace {
+	return nil
+}
+
+// remoteCaller is caller data received from another process. It has no program counter: the frame
+// it describes did not run in this one, so everything it knows was recorded by the sender.
+type remoteCaller struct {
+	file string
+	fn   string
+	line int
+}
+
+// File implements Caller.
+func (caller *remoteCaller) File() string {
+	return caller.file
+}
+
+// Func implements Caller.
+func (caller *remoteCaller) Func() string {
+	return caller.fn
+}
+
+// Line implements Caller.
+func (caller *remoteCaller) Line() int {
+	return caller.line
+}
+
+// Pc implements Caller. A remote frame has no program counter in this process.
+func (caller *remoteCaller) Pc() uintptr {
+	return 0
+}
+
+// String implements fmt.Stringer.
+func (caller *remoteCaller) String() string {
+	return fmt.Sprintf("%s:%d", caller.fn, caller.line)
+}
+
+// Trace implements Caller. Only the frame itself was received, so it is the whole trace.
+func (caller *remoteCaller) Trace() std_caller.Trace {
+	return std_caller.Trace{caller}
+}
@@ -0,0 +1,76 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// TestCallerCapturesDeepStacks: a caller stores a fixed number of program counters inline and
+// spills deeper stacks into a separate allocation. Both must yield the whole trace.
+func TestCallerCapturesDeepStacks(t *testing.T) {
+	for _, depth := range []int{0, 10, 31, 32, 33, 100, 500} {
+		var err *errors.E
+		nested(depth, func() { err = errors.New("deep") })
+
+		trace := errors.Caller(err).Trace()
+		count := 0
+		for _, frame := range trace {
+			if strings.HasSuffix(frame.Func(), ".nested") {
+				count++
+			}
+		}
+		if depth+1 != count {
+			t.Errorf("depth %d: trace has %d nested frames, want %d", depth, count, depth+1)
+		}
+		if !strings.Contains(errors.Caller(err).Func(), "TestCallerCapturesDeepStacks") {
+			t.Errorf("depth %d: caller is %s, want the call site", depth, errors.Caller(err).Func())
+		}
+	}
+}
+
+// TestCallerResolvesConsistentlyUnderConcurrency: symbolization is deferred to the first read, and
+// the first read may happen on several goroutines at once. Run with -race.
+func TestCallerResolvesConsistentlyUnderConcurrency(t *testing.T) {
+	err := errors.Trace(errors.Wrap(errors.New("inner"), "outer"))
+	clr := errors.Caller(err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = fmt.Sprintf("%s %s %d %d", clr.File(), clr.Func(), clr.Line(), len(clr.Trace()))
+		}(i)
+	}
+	wg.Wait()
+	for i, result := range results {
+		if results[0] != result {
+			t.Errorf("goroutine %d resolved %q, goroutine 0 resolved %q", i, result, results[0])
+		}
+	}
+}
+
+// TestTraceAppendsTheWrappedTrace: Trace's caller is its own call site followed by the trace of
+// the error it decorates.
+func TestTraceAppendsTheWrappedTrace(t *testing.T) {
+	inner := errors.New("inner")
+	traced := errors.Trace(inner)
+
+	trace := errors.Caller(traced).Trace()
+	innerTrace := errors.Caller(inner).Trace()
+	if len(innerTrace)+1 != len(trace) {
+		t.Fatalf("trace has %d frames, want %d", len(trace), len(innerTrace)+1)
+	}
+	if trace[0].Line() != errors.Caller(traced).Line() || trace[1].Line() != innerTrace[0].Line() {
+		t.Errorf("trace starts %d, %d; want %d, %d",
+			trace[0].Line(), trace[1].Line(), errors.Caller(traced).Line(), innerTrace[0].Line())
+	}
+	if nil == errors.Trace(&custom{msg: "no caller data"}) {
+		t.Error("Trace of an error without caller data returned nil")
+	}
+}
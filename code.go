@@ -0,0 +1,36 @@
+package errors
+
+// Code is a machine-readable error code: a stable identifier for a failure that a program can
+// branch on, such as "user_not_found" or "quota_exceeded".
+//
+// A message is written for people and is reworded freely, so deciding how to respond to an error by
+// matching its Error() text breaks the first time someone improves a Wrap message. A code is part of
+// the API instead, and survives any amount of wrapping.
+type Code string
+
+// WithCode returns a copy of this frame carrying code. The receiver is not modified, see WithField.
+func (e *E) WithCode(code Code) *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.code = code
+	return cp
+}
+
+// CodeOf returns the code nearest the top of err's chain, or the empty Code if no link carries one.
+//
+// The chain is searched the way Is and As search it: each frame's annotation as well as its cause,
+// and every branch of an error wrapping several causes, in order. The outermost code wins because
+// it was set by the code that knew the most about what the failure means to its caller.
+func CodeOf(err error) Code {
+	var code Code
+	walk(err, func(link error) bool {
+		if e, ok := link.(*E); ok && nil != e && "" != e.code {
+			code = e.code
+			return true
+		}
+		return false
+	})
+	return code
+}
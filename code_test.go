@@ -0,0 +1,78 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+const (
+	codeNotFound errors.Code = "user_not_found"
+	codeStorage  errors.Code = "storage_failure"
+)
+
+func TestCodeOfFindsTheNearestCode(t *testing.T) {
+	inner := errors.New("no row").WithCode(codeStorage)
+	for name, tc := range map[string]struct {
+		err  error
+		want errors.Code
+	}{
+		"nil":                    {nil, ""},
+		"no code anywhere":       {errors.Wrap(sentinel, "a"), ""},
+		"on the error itself":    {inner, codeStorage},
+		"below a reworded wrap":  {errors.Wrap(errors.Wrap(inner, "a"), "b"), codeStorage},
+		"outer code wins":        {errors.Wrap(inner, "lookup").WithCode(codeNotFound), codeNotFound},
+		"through fmt.Errorf":     {fmt.Errorf("f: %w", inner), codeStorage},
+		"in a join branch":       {std_errors.Join(other, errors.Wrap(inner, "a")), codeStorage},
+		"behind a wrapped join":  {errors.Wrap(std_errors.Join(other, inner), "a"), codeStorage},
+		"in a WrapE annotation":  {errors.WrapE(sentinel, inner), codeStorage},
+		"first branch that has":  {std_errors.Join(other, errors.New("x").WithCode(codeNotFound), inner), codeNotFound},
+		"foreign with no code":   {&custom{msg: "foreign"}, ""},
+		"traced keeps the code":  {errors.Trace(inner), codeStorage},
+		"tracked keeps the code": {errors.Track(inner), codeStorage},
+	} {
+		if got := errors.CodeOf(tc.err); tc.want != got {
+			t.Errorf("%s: CodeOf = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestWithCodeDoesNotModifyTheReceiver(t *testing.T) {
+	base := errors.New("base")
+	coded := base.WithCode(codeNotFound)
+	if "" != errors.CodeOf(base) {
+		t.Errorf("the original frame gained a code: %q", errors.CodeOf(base))
+	}
+	if !errors.Is(coded, base) {
+		t.Error("a coded copy no longer matches the original")
+	}
+	var nilE *errors.E
+	if nil != nilE.WithCode(codeNotFound) {
+		t.Error("WithCode on a nil receiver returned a non-nil error")
+	}
+}
+
+func TestCodeIsRenderedPerFrame(t *testing.T) {
+	err := errors.Wrap(errors.New("inner").WithCode(codeStorage), "outer")
+	raw, marshalErr := json.Marshal(err)
+	if nil != marshalErr {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(raw, &entries); nil != err {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := entries[0]["code"]; ok {
+		t.Errorf("a frame without a code rendered one: %s", raw)
+	}
+	if string(codeStorage) != entries[1]["code"] {
+		t.Errorf("inner frame code = %v, want %q", entries[1]["code"], codeStorage)
+	}
+	if out := fmt.Sprintf("%#+v", err); !strings.Contains(out, `"code":"storage_failure"`) {
+		t.Errorf("%%#+v omitted the code: %s", out)
+	}
+}
@@ -11,8 +11,25 @@ import (
 // the exported package methods as a convenience.
 type E struct {
 	caller std_caller.Caller
+	code   Code
 	err    error
+	fields map[string]interface{}
+	// given reports that err is an error WrapE was given, rather than a message this package
+	// created from a string, see Fingerprint.
+	given  bool
+	id     string
+	kind   *Kind
 	prev   error
+	public string
+	remote bool
+	retry  *retryHint
+}
+
+// clone returns a shallow copy of this frame. The With* methods build on it so that decorating an
+// error never modifies a value someone else may already be holding.
+func (e *E) clone() *E {
+	cp := *e
+	return &cp
 }
 
 // Caller implements std_error.Caller.
@@ -46,6 +63,10 @@ func (e *E) Error() string {
 	if nil == e.prev {
 		return e.err.Error()
 	}
+	// A foreign link received from another process; see FromFrames.
+	if remote, ok := e.err.(*remoteError); ok && remote.whole {
+		return remote.msg
+	}
 	return e.err.Error() + ": " + e.prev.Error()
 }
 
@@ -70,7 +91,15 @@ func (e *E) Is(test error) bool {
 	if comparableErrors(e, test) && error(e) == test {
 		return true
 	}
-	if comparableErrors(e.err, test) && e.err == test {
+	// The annotation is asked rather than compared, and searched the way walk searches it, so that
+	// a Kind given to WrapE matches its ancestors, as the frame's own kind does, and Is agrees with
+	// KindOf about what the chain contains.
+	if nil != e.err && Is(e.err, test) {
+		return true
+	}
+
+	// A frame's kind matches that kind and every ancestor of it -- see Kind.
+	if nil != e.kind && e.kind.Is(test) {
 		return true
 	}
 
@@ -152,3 +181,32 @@ func list(e error) []error {
 
 	return ret
 }
+
+// walk calls fn for err and every error beneath it, outermost first, until fn returns true, and
+// reports whether it did.
+//
+// It visits what Is and As visit: each link of the Unwrap chain, the annotation a WrapE frame holds
+// off the chain, and every branch of an error implementing Unwrap() []error. Lookups that want "the
+// nearest X in the chain" use it so that they agree with Is about what the chain contains.
+func walk(err error, fn func(error) bool) bool {
+	for nil != err {
+		if fn(err) {
+			return true
+		}
+		if e, ok := err.(*E); ok && nil != e && nil != e.err {
+			if walk(e.err, fn) {
+				return true
+			}
+		}
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, branch := range multi.Unwrap() {
+				if walk(branch, fn) {
+					return true
+				}
+			}
+			return false
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
@@ -20,7 +20,7 @@ func TestMarshaller(t *testing.T) {
 	byts, err := json.Marshal(err)
 	assert.Equal(nil, err, "err is not nil")
 	assert.Equal(
-		"[{\"caller\":\"#0 error_test.go:19 (github.com/bdlm/errors/v2_test.TestMarshaller)\",\"error\":\"test 1\"}]",
+		"[{\"caller\":\"#0 error_test.go:19 (github.com/bdlm/errors/v2_test.TestMarshaller)\",\"error\":\"test 1\",\"fingerprint\":\"25f1fba48b2a282c374efb25b9b7e02c\"}]",
 		string(byts),
 		"JSON did not encode properly",
 	)
@@ -91,7 +91,7 @@ func ExampleE_Format_jsonDetailPreformat() {
 func ExampleE_Format_jsonTrace() {
 	err := loadConfig()
 	fmt.Printf("%#+v", err)
-	// Output: [{"caller":"#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)","error":"service configuration could not be loaded"},{"caller":"#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)","error":"could not decode configuration data"},{"caller":"#2 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig)","error":"could not read configuration file"},{"caller":"#3 n/a","error":"read: end of input"}]
+	// Output: [{"caller":"#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)","error":"service configuration could not be loaded","fingerprint":"6527b369add47e8f0d98e25c8db84d18"},{"caller":"#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)","error":"could not decode configuration data"},{"caller":"#2 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig)","error":"could not read configuration file"},{"caller":"#3 n/a","error":"read: end of input"}]
 }
 
 func ExampleE_Format_jsonTracePreformat() {
@@ -100,7 +100,8 @@ func ExampleE_Format_jsonTracePreformat() {
 	// Output: [
 	//     {
 	//         "caller": "#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)",
-	//         "error": "service configuration could not be loaded"
+	//         "error": "service configuration could not be loaded",
+	//         "fingerprint": "6527b369add47e8f0d98e25c8db84d18"
 	//     },
 	//     {
 	//         "caller": "#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)",
@@ -122,7 +123,7 @@ func ExampleE_MarshalJSON_marshal() {
 	jsn, _ := json.Marshal(err)
 
 	fmt.Println(string(jsn))
-	// Output: [{"caller":"#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)","error":"service configuration could not be loaded"},{"caller":"#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)","error":"could not decode configuration data"},{"caller":"#2 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig)","error":"could not read configuration file"},{"caller":"#3 n/a","error":"read: end of input"}]
+	// Output: [{"caller":"#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)","error":"service configuration could not be loaded","fingerprint":"6527b369add47e8f0d98e25c8db84d18"},{"caller":"#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)","error":"could not decode configuration data"},{"caller":"#2 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig)","error":"could not read configuration file"},{"caller":"#3 n/a","error":"read: end of input"}]
 }
 
 func ExampleE_MarshalJSON_marshalIndent() {
@@ -133,7 +134,8 @@ func ExampleE_MarshalJSON_marshalIndent() {
 	// Output: [
 	//     {
 	//         "caller": "#0 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig)",
-	//         "error": "service configuration could not be loaded"
+	//         "error": "service configuration could not be loaded",
+	//         "fingerprint": "6527b369add47e8f0d98e25c8db84d18"
 	//     },
 	//     {
 	//         "caller": "#1 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig)",
@@ -180,7 +182,7 @@ func ExampleWrap() {
 	err = errors.Wrap(err, "loadConfig returned an error")
 
 	fmt.Printf("% +v", err)
-	// Output: loadConfig returned an error - #0 examples_test.go:180 (github.com/bdlm/errors/v2_test.ExampleWrap);
+	// Output: loadConfig returned an error - #0 examples_test.go:182 (github.com/bdlm/errors/v2_test.ExampleWrap);
 	// service configuration could not be loaded - #1 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig);
 	// could not decode configuration data - #2 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig);
 	// could not read configuration file - #3 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig);
@@ -200,7 +202,7 @@ func ExampleWrapE() {
 	}
 
 	fmt.Printf("% +v", err)
-	// Output: rpc error: code = Internal desc = internal server error - #0 examples_test.go:199 (github.com/bdlm/errors/v2_test.ExampleWrapE);
+	// Output: rpc error: code = Internal desc = internal server error - #0 examples_test.go:201 (github.com/bdlm/errors/v2_test.ExampleWrapE);
 	// service configuration could not be loaded - #1 mocks_test.go:16 (github.com/bdlm/errors/v2_test.loadConfig);
 	// could not decode configuration data - #2 mocks_test.go:21 (github.com/bdlm/errors/v2_test.decodeConfig);
 	// could not read configuration file - #3 mocks_test.go:26 (github.com/bdlm/errors/v2_test.readConfig);
@@ -176,6 +176,16 @@ func New(msg string) *E {
 	}
 }
 
+// NewSkip is New for a helper function that creates errors on behalf of its caller. It leaves out
+// an additional skip frames, as NewCallerSkip does, so NewSkip(1, msg) called from a helper reports
+// the function that called the helper. A helper marked with Helper needs no skip at all.
+func NewSkip(skip int, msg string) *E {
+	return &E{
+		caller: newCallerSkip(skip),
+		err:    std_errors.New(msg),
+	}
+}
+
 // Trace adds an additional caller line to the error trace trace on an error
 // to aid in debugging and forensic analysis.
 func Trace(e error) *E {
@@ -183,10 +193,10 @@ func Trace(e error) *E {
 		return nil
 	}
 
-	clr := NewCaller().(*caller)
-	clr.trace = std_caller.Trace{clr.trace[0]}
-	if stdClr, ok := e.(std_error.Caller); ok {
-		clr.trace = append(clr.trace, stdClr.Caller().Trace()...)
+	// The trace is this call site followed by the error's own trace, resolved only when asked for.
+	clr := newCaller(3)
+	if stdClr, ok := e.(std_error.Caller); ok && nil != stdClr.Caller() {
+		clr.next = stdClr.Caller()
 	}
 
 	// prev, NOT err. Trace adds a caller line; it does not annotate. Holding the wrapped error in
@@ -214,8 +224,10 @@ func Track(e error) *E {
 		return nil
 	}
 
-	// Adopt the error's own caller when it has one, so tracking does not overwrite the origin.
-	clr := NewCaller()
+	// Adopt the error's own caller when it has one, so tracking does not overwrite the origin. The
+	// stack is captured once and shared: both frames were created at the same call site.
+	tracked := NewCaller()
+	clr := tracked
 	if stdClr, ok := e.(std_error.Caller); ok && nil != stdClr.Caller() {
 		clr = stdClr.Caller()
 	}
@@ -223,7 +235,7 @@ func Track(e error) *E {
 	return &E{
 		caller: clr,
 		prev: &E{
-			caller: NewCaller(),
+			caller: tracked,
 			err:    std_errors.New("(tracked)"),
 			prev:   e,
 		},
@@ -248,17 +260,38 @@ func Unwrap(err error) error {
 // arguments there is nothing to interpolate, and interpreting it anyway corrupts any message
 // containing a percent sign.
 func Wrap(e error, msg string, data ...interface{}) *E {
+	return wrap(e, annotation(msg, data), false)
+}
+
+// annotation is the message of an error created by Wrap or WrapSkip.
+func annotation(msg string, data []interface{}) error {
 	if 0 == len(data) {
-		return WrapE(e, std_errors.New(msg))
+		return std_errors.New(msg)
 	}
-	return WrapE(e, fmt.Errorf(msg, data...))
+	return fmt.Errorf(msg, data...)
 }
 
 // WrapE returns a new error that wraps the provided error.
 func WrapE(e, err error) *E {
+	return wrap(e, err, true)
+}
+
+// wrap is Wrap and WrapE, with the caller data recorded at the exported function's call site.
+func wrap(e, err error, given bool) *E {
 	return &E{
-		caller: NewCaller(),
+		caller: newCaller(4),
 		err:    err,
+		given:  given,
+		prev:   e,
+	}
+}
+
+// WrapSkip is Wrap for a helper function that wraps errors on behalf of its caller. It leaves out
+// an additional skip frames, as NewCallerSkip does.
+func WrapSkip(skip int, e error, msg string, data ...interface{}) *E {
+	return &E{
+		caller: newCallerSkip(skip),
+		err:    annotation(msg, data),
 		prev:   e,
 	}
 }
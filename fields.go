@@ -0,0 +1,53 @@
+package errors
+
+// WithField returns a copy of this frame carrying key set to value.
+//
+// Fields are structured context -- a user ID, a tenant, a retry count -- that belongs with the
+// error but not in its message, where it can only be recovered by parsing a string. They are
+// rendered per frame by MarshalJSON and the JSON format verbs, and merged across the chain by the
+// package-level Fields.
+//
+// The receiver is not modified. An error is shared freely once created, so attaching context to it
+// in place would be a data race for anyone already holding it; the copy keeps this frame's caller,
+// message and cause, so Is still matches it against the original.
+func (e *E) WithField(key string, value interface{}) *E {
+	return e.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a copy of this frame carrying every key in fields, in addition to any fields
+// it already had. A key that is already present is overwritten.
+func (e *E) WithFields(fields map[string]interface{}) *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.fields = make(map[string]interface{}, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		cp.fields[k] = v
+	}
+	for k, v := range fields {
+		cp.fields[k] = v
+	}
+	return cp
+}
+
+// Fields returns the fields attached anywhere in err's chain, merged into a single map. The chain is
+// searched the same way CodeOf searches it, annotations and every branch of a multi-error included.
+//
+// When the same key is set at several depths the OUTERMOST value wins: the frame nearest the
+// caller is the one that most recently had the context in hand, so it is the one to believe. The
+// returned map is a new value and may be modified freely. It is never nil.
+func Fields(err error) map[string]interface{} {
+	ret := map[string]interface{}{}
+	walk(err, func(link error) bool {
+		if e, ok := link.(*E); ok && nil != e {
+			for k, v := range e.fields {
+				if _, ok := ret[k]; !ok {
+					ret[k] = v
+				}
+			}
+		}
+		return false
+	})
+	return ret
+}
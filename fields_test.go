@@ -0,0 +1,107 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestWithFieldDoesNotModifyTheReceiver(t *testing.T) {
+	base := errors.New("base")
+	decorated := base.WithField("user", 42)
+
+	if 0 != len(errors.Fields(base)) {
+		t.Errorf("the original frame gained fields: %v", errors.Fields(base))
+	}
+	if 42 != errors.Fields(decorated)["user"] {
+		t.Errorf("Fields = %v, want user=42", errors.Fields(decorated))
+	}
+	if !errors.Is(decorated, base) || !std_errors.Is(decorated, base) {
+		t.Error("a decorated copy no longer matches the original")
+	}
+	if "base" != decorated.Error() {
+		t.Errorf("decorating changed the message: %q", decorated.Error())
+	}
+}
+
+func TestFieldsMergeAcrossTheChainOuterWins(t *testing.T) {
+	inner := errors.New("inner").WithFields(map[string]interface{}{"tenant": "a", "retry": 1})
+	middle := fmt.Errorf("fmt layer: %w", inner)
+	outer := errors.Wrap(middle, "outer").WithField("retry", 2)
+
+	got := errors.Fields(outer)
+	if "a" != got["tenant"] {
+		t.Errorf("tenant = %v, want the inner value to survive wrapping", got["tenant"])
+	}
+	if 2 != got["retry"] {
+		t.Errorf("retry = %v, want the outer value to win", got["retry"])
+	}
+
+	got["tenant"] = "mutated"
+	if "a" != errors.Fields(outer)["tenant"] {
+		t.Error("the returned map aliases the error's own fields")
+	}
+}
+
+func TestFieldsNilSafety(t *testing.T) {
+	var nilE *errors.E
+	if nil != nilE.WithField("k", "v") {
+		t.Error("WithField on a nil receiver returned a non-nil error")
+	}
+	if got := errors.Fields(nil); nil == got || 0 != len(got) {
+		t.Errorf("Fields(nil) = %#v, want an empty map", got)
+	}
+}
+
+func TestFieldsAreRenderedPerFrame(t *testing.T) {
+	err := errors.Wrap(errors.New("inner").WithField("user", "u1"), "outer").WithField("path", "/x")
+
+	raw, marshalErr := json.Marshal(err)
+	if nil != marshalErr {
+		t.Fatalf("marshal: %v", marshalErr)
+	}
+	var entries []struct {
+		Error  string                 `json:"error"`
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal(raw, &entries); nil != err {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if 2 != len(entries) {
+		t.Fatalf("entries = %d, want 2: %s", len(entries), raw)
+	}
+	if "/x" != entries[0].Fields["path"] || nil != entries[0].Fields["user"] {
+		t.Errorf("outer frame fields = %v, want only its own", entries[0].Fields)
+	}
+	if "u1" != entries[1].Fields["user"] {
+		t.Errorf("inner frame fields = %v", entries[1].Fields)
+	}
+
+	if out := fmt.Sprintf("%#v", err); !strings.Contains(out, `"fields":{"path":"/x"}`) {
+		t.Errorf("%%#v omitted the fields: %s", out)
+	}
+	if out := fmt.Sprintf("%#+v", err); !strings.Contains(out, `"fields":{"user":"u1"}`) {
+		t.Errorf("%%#+v omitted the inner frame's fields: %s", out)
+	}
+	if out := fmt.Sprintf("%#v", errors.New("plain")); strings.Contains(out, "fields") {
+		t.Errorf("a frame with no fields rendered a fields member: %s", out)
+	}
+}
+
+func TestFieldsSearchAnnotationsAndBranches(t *testing.T) {
+	annotation := errors.New("annotation").WithField("from", "annotation")
+	branch := errors.New("branch").WithFields(map[string]interface{}{"from": "branch", "shard": 3})
+	err := errors.WrapE(errors.Join(errors.New("sibling"), branch), annotation).WithField("request", "r1")
+
+	got := errors.Fields(err)
+	if "r1" != got["request"] || 3 != got["shard"] {
+		t.Errorf("Fields = %v, want the fields of the annotation and of every branch", got)
+	}
+	if "annotation" != got["from"] {
+		t.Errorf("from = %v, want the annotation, which is outer to the branches, to win", got["from"])
+	}
+}
@@ -0,0 +1,186 @@
+package errors
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// frameFilter decides which frames are left out of callers and traces. It is immutable once
+// published, so reading it needs no lock; HidePackages and HideStdlib publish a modified copy.
+type frameFilter struct {
+	packages []string
+	stdlib   bool
+}
+
+var (
+	filter   atomic.Value
+	filterMu sync.Mutex
+
+	// helpers holds the names of the functions marked with Helper, and helperPCs the program
+	// counters Helper has been called from, so that marking a function again is only a lookup.
+	helpers   sync.Map
+	helperPCs sync.Map
+
+	// pkgPath is this package's import path as the runtime reports it, which is what makes the
+	// filter independent of where the source lives: vendored, forked or built with -trimpath, the
+	// function names of this package still start with it.
+	pkgPath = reflect.TypeOf(E{}).PkgPath()
+)
+
+func init() {
+	filter.Store(&frameFilter{
+		packages: []string{pkgPath},
+	})
+}
+
+// HidePackages leaves the frames of the named packages, and of their subpackages, out of every
+// caller and trace, as this package's own frames are. It is for layers that create errors on
+// behalf of their callers -- a project's own error helpers, say -- so that an error reports the
+// code that called the helper rather than the helper itself.
+//
+// Packages are matched by import path against the function names the runtime reports, not by file
+// path, so the match does not depend on where the source is checked out or on -trimpath. Test
+// packages, and frames in _test.go files, are never hidden.
+//
+// The filter is applied when caller data is first read, not when an error is created, so configure
+// it during initialization. The returned function unhides the packages again, for a test that hides
+// packages temporarily; calling it more than once is harmless.
+func HidePackages(pkgs ...string) (restore func()) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	current := filter.Load().(*frameFilter)
+	next := &frameFilter{
+		packages: append(append([]string{}, current.packages...), pkgs...),
+		stdlib:   current.stdlib,
+	}
+	filter.Store(next)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unhidePackages(pkgs)
+		})
+	}
+}
+
+// unhidePackages removes pkgs from the filter, the most recently hidden occurrence of each, so
+// that packages hidden since, or hidden twice, stay hidden.
+func unhidePackages(pkgs []string) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	current := filter.Load().(*frameFilter)
+	packages := append([]string{}, current.packages...)
+	for _, pkg := range pkgs {
+		for i := len(packages) - 1; 0 <= i; i-- {
+			if pkg == packages[i] {
+				packages = append(packages[:i], packages[i+1:]...)
+				break
+			}
+		}
+	}
+	filter.Store(&frameFilter{
+		packages: packages,
+		stdlib:   current.stdlib,
+	})
+}
+
+// HideStdlib sets whether frames of the standard library -- runtime, testing, net/http and the
+// rest -- are left out of every caller and trace. They are kept by default.
+//
+// A package is taken to be part of the standard library when the first element of its import path
+// contains no dot, as is the convention for everything else. A module whose path has no dot is
+// therefore treated as standard library too; its main package never is.
+func HideStdlib(hide bool) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	current := filter.Load().(*frameFilter)
+	filter.Store(&frameFilter{
+		packages: current.packages,
+		stdlib:   hide,
+	})
+}
+
+// Helper marks the calling function as a helper, as testing.T.Helper does: when an error is
+// created, a helper's frames at the top of the stack are left out, so the error reports the
+// function that called the helper -- or that helper's caller, if it is a helper too. A validation
+// function that calls New on behalf of its caller marks itself once, on entry, and needs no skip
+// count.
+//
+// Unlike HidePackages, only the marked function is affected, and only where it is the origin of an
+// error: further down a trace its frames are kept. Marking a function more than once is harmless.
+func Helper() {
+	var pcs [1]uintptr
+	if 0 == runtime.Callers(2, pcs[:]) {
+		return
+	}
+	if _, ok := helperPCs.Load(pcs[0]); ok {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if "" != frame.Function {
+		helpers.Store(frame.Function, struct{}{})
+	}
+	helperPCs.Store(pcs[0], struct{}{})
+}
+
+// isHelper reports whether the function fn has been marked with Helper.
+func isHelper(fn string) bool {
+	_, ok := helpers.Load(fn)
+	return ok
+}
+
+// Hidden reports whether the frame of function fn, in file, is left out of callers and traces: it
+// belongs to this package, to a package hidden with HidePackages, or to the standard library when
+// HideStdlib is set. fn is the function's name as the runtime reports it, as Caller's Func is.
+func Hidden(fn, file string) bool {
+	if strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	pkg := funcPackage(fn)
+	if "" == pkg || strings.HasSuffix(pkg, "_test") {
+		return false
+	}
+	current := filter.Load().(*frameFilter)
+	for _, hide := range current.packages {
+		if pkg == hide || strings.HasPrefix(pkg, hide+"/") {
+			return true
+		}
+	}
+	return current.stdlib && stdlib(pkg)
+}
+
+// InApp reports whether the frame of function fn, in file, is the application's own: neither
+// Hidden, nor the standard library's, whether or not HideStdlib is set. Error reporting tools use
+// the distinction to collapse the frames the application did not write.
+func InApp(fn, file string) bool {
+	return !Hidden(fn, file) && !stdlib(funcPackage(fn))
+}
+
+// stdlib reports whether the package pkg is part of the standard library, see HideStdlib.
+func stdlib(pkg string) bool {
+	if "main" == pkg || "" == pkg || strings.HasSuffix(pkg, "_test") {
+		return false
+	}
+	first := pkg
+	if i := strings.Index(first, "/"); 0 <= i {
+		first = first[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// funcPackage returns the import path of the package a function belongs to, given its name as the
+// runtime reports it: "github.com/bdlm/errors/v2.(*E).Format" belongs to "github.com/bdlm/errors/v2".
+func funcPackage(fn string) string {
+	// Type arguments can contain slashes and dots of their own, and are not part of the path.
+	if i := strings.Index(fn, "["); 0 <= i {
+		fn = fn[:i]
+	}
+	slash := strings.LastIndex(fn, "/")
+	if i := strings.Index(fn[slash+1:], "."); 0 <= i {
+		return fn[:slash+1+i]
+	}
+	return fn
+}
@@ -0,0 +1,170 @@
+package errors_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	std_caller "github.com/bdlm/std/v2/caller"
+)
+
+func funcs(trace std_caller.Trace) []string {
+	ret := []string{}
+	for _, frame := range trace {
+		ret = append(ret, frame.Func())
+	}
+	return ret
+}
+
+// TestThisPackageIsHiddenByFunctionName: the package's own frames were once recognised by a file
+// path containing "github.com/bdlm/errors", which is not where the source lives when it is vendored,
+// forked, or simply checked out somewhere else -- and then every error reported NewCaller as its
+// origin. They are recognised by package now.
+func TestThisPackageIsHiddenByFunctionName(t *testing.T) {
+	for name, err := range map[string]*errors.E{
+		"New":    errors.New("x"),
+		"Errorf": errors.Errorf("x"),
+		"Wrap":   errors.Wrap(sentinel, "x"),
+		"WrapE":  errors.WrapE(sentinel, other),
+		"Trace":  errors.Trace(sentinel),
+		"Track":  errors.Track(sentinel),
+	} {
+		clr := errors.Caller(err)
+		if !strings.HasSuffix(clr.Func(), ".TestThisPackageIsHiddenByFunctionName") {
+			t.Errorf("%s: caller is %s, want the test", name, clr.Func())
+		}
+		for _, fn := range funcs(clr.Trace()) {
+			if strings.HasPrefix(fn, "github.com/bdlm/errors/v2.") {
+				t.Errorf("%s: the trace includes this package's frame %s", name, fn)
+			}
+		}
+	}
+}
+
+func TestHidePackages(t *testing.T) {
+	var err *errors.E
+	values := []int{2, 1}
+	sort.Slice(values, func(i, j int) bool {
+		if nil == err {
+			err = errors.New("inside a callback")
+		}
+		return values[i] < values[j]
+	})
+
+	hasSort := func() bool {
+		for _, fn := range funcs(errors.Caller(err).Trace()) {
+			if strings.HasPrefix(fn, "sort.") {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasSort() {
+		t.Fatalf("premise: the trace should pass through package sort: %v", funcs(errors.Caller(err).Trace()))
+	}
+
+	// The filter is applied when caller data is first read, so hide before the error is created.
+	t.Cleanup(errors.HidePackages("sort"))
+	err = nil
+	sort.Slice(values, func(i, j int) bool {
+		if nil == err {
+			err = errors.New("inside a callback")
+		}
+		return values[i] < values[j]
+	})
+	if hasSort() {
+		t.Errorf("a hidden package is still in the trace: %v", funcs(errors.Caller(err).Trace()))
+	}
+	if !strings.Contains(errors.Caller(err).Func(), "TestHidePackages") {
+		t.Errorf("caller is %s, want the test", errors.Caller(err).Func())
+	}
+}
+
+func TestHideStdlib(t *testing.T) {
+	errors.HideStdlib(true)
+	defer errors.HideStdlib(false)
+
+	trace := funcs(errors.Caller(errors.New("x")).Trace())
+	if 1 != len(trace) || !strings.HasSuffix(trace[0], ".TestHideStdlib") {
+		t.Errorf("trace = %v, want only the test function", trace)
+	}
+
+	errors.HideStdlib(false)
+	trace = funcs(errors.Caller(errors.New("x")).Trace())
+	var hasTesting bool
+	for _, fn := range trace {
+		hasTesting = hasTesting || strings.HasPrefix(fn, "testing.")
+	}
+	if !hasTesting {
+		t.Errorf("trace = %v, want the standard library's frames back", trace)
+	}
+}
+
+func callerSkip(skip int) std_caller.Caller {
+	return errors.NewCallerSkip(skip)
+}
+
+func TestNewCallerSkip(t *testing.T) {
+	if clr := callerSkip(0); !strings.HasSuffix(clr.Func(), ".callerSkip") {
+		t.Errorf("skip 0: caller is %s, want the helper", clr.Func())
+	}
+	clr := callerSkip(1)
+	if !strings.HasSuffix(clr.Func(), ".TestNewCallerSkip") {
+		t.Errorf("skip 1: caller is %s, want the helper's caller", clr.Func())
+	}
+	if trace := clr.Trace(); 0 == len(trace) || trace[0].Func() != clr.Func() {
+		t.Errorf("skip 1: the trace does not start at the caller: %v", funcs(trace))
+	}
+	if clr := callerSkip(1000); "" != clr.Func() || 0 != len(clr.Trace()) {
+		t.Errorf("skipping past the end of the stack: caller = %q, trace = %v", clr.Func(), funcs(clr.Trace()))
+	}
+	if clr := callerSkip(-1); !strings.HasSuffix(clr.Func(), ".callerSkip") {
+		t.Errorf("a negative skip should be treated as zero: caller is %s", clr.Func())
+	}
+}
+
+func TestHiddenAndInApp(t *testing.T) {
+	for _, tc := range []struct {
+		fn, file      string
+		hidden, inApp bool
+	}{
+		{"github.com/bdlm/errors/v2.(*E).Format", "/src/errors/format.go", true, false},
+		{"github.com/bdlm/errors/v2_test.TestHiddenAndInApp", "/src/errors/filter_test.go", false, true},
+		{"example.com/svc.(*Server).Handle", "/src/svc/server.go", false, true},
+		{"net/http.HandlerFunc.ServeHTTP", "/go/src/net/http/server.go", false, false},
+		{"runtime.goexit", "/go/src/runtime/asm_amd64.s", false, false},
+		{"main.main", "/src/svc/main.go", false, true},
+	} {
+		if got := errors.Hidden(tc.fn, tc.file); tc.hidden != got {
+			t.Errorf("Hidden(%s) = %t, want %t", tc.fn, got, tc.hidden)
+		}
+		if got := errors.InApp(tc.fn, tc.file); tc.inApp != got {
+			t.Errorf("InApp(%s) = %t, want %t", tc.fn, got, tc.inApp)
+		}
+	}
+}
+
+func TestHidePackagesRestore(t *testing.T) {
+	restoreOuter := errors.HidePackages("testing")
+	defer restoreOuter()
+	restore := errors.HidePackages("testing")
+
+	hasTesting := func() bool {
+		for _, fn := range funcs(errors.Caller(errors.New("x")).Trace()) {
+			if strings.HasPrefix(fn, "testing.") {
+				return true
+			}
+		}
+		return false
+	}
+	restore()
+	restore()
+	if hasTesting() {
+		t.Error("restoring one call unhid a package another call still hides")
+	}
+	restoreOuter()
+	if !hasTesting() {
+		t.Error("the package is still hidden once every call is restored")
+	}
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Fingerprint returns a hash of the structure of err's chain, for grouping and deduplicating
+// occurrences of the same failure. It returns the empty string for a nil error.
+//
+// The hash covers what identifies a failure rather than an occurrence of it: for each *E link its
+// code, its kind, the function and file name it was created in, and what it annotates the chain
+// with -- the whole structure of an error given to WrapE, which for a sentinel of another type is
+// its type and message. For each link of another type it covers that type, and, if it wraps
+// nothing, its message, which is what tells one sentinel from another. An *E that wraps nothing
+// and was created in a package initializer is a sentinel too, and is told apart by its message,
+// since sentinels declared in the same file share their origin. The branches of an error with
+// several causes are covered in order.
+//
+// Line numbers, other messages of *E links and fields are not covered, so the fingerprint
+// survives interpolated values, unrelated edits, deploys and hosts, and differs only when the
+// chain's shape does. A chain rebuilt with FromFrames or FromJSON has one of its own, since the
+// types of its foreign links did not survive the trip.
+//
+// The result is a string of 32 hex digits, suitable as a map key.
+func Fingerprint(err error) string {
+	if nil == err {
+		return ""
+	}
+	hash := sha256.New()
+	fingerprint(hash, err)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// fingerprint writes the structure Fingerprint hashes to w, one line per link.
+func fingerprint(w io.Writer, err error) {
+	for ; nil != err; err = Unwrap(err) {
+		switch link := err.(type) {
+		case *E:
+			if nil == link {
+				return
+			}
+			fmt.Fprintf(w, "E %q %q", link.code, link.kind.Name())
+			clr := link.Caller()
+			if nil != clr {
+				fmt.Fprintf(w, " %q %q", clr.Func(), path.Base(clr.File()))
+			}
+			switch annotation := link.err.(type) {
+			case nil:
+			case *E:
+				fmt.Fprintln(w, " [")
+				fingerprint(w, annotation)
+				fmt.Fprint(w, "]")
+			default:
+				if link.given {
+					fmt.Fprintf(w, " T %q %q", fmt.Sprintf("%T", annotation), annotation.Error())
+				} else if nil == link.prev && nil != clr && initializer(clr.Func()) {
+					fmt.Fprintf(w, " %q", annotation.Error())
+				}
+			}
+		case *Kind:
+			fmt.Fprintf(w, "K %q", link.Name())
+		case *joined:
+			fmt.Fprint(w, "J")
+		default:
+			fmt.Fprintf(w, "T %q", fmt.Sprintf("%T", link))
+			if _, ok := link.(interface{ Unwrap() []error }); !ok && nil == Unwrap(link) {
+				fmt.Fprintf(w, " %q", link.Error())
+			}
+		}
+		fmt.Fprintln(w)
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, branch := range multi.Unwrap() {
+				fmt.Fprintln(w, "(")
+				fingerprint(w, branch)
+				fmt.Fprintln(w, ")")
+			}
+			return
+		}
+	}
+}
+
+// initializer reports whether the function fn, named as the runtime reports it, is a package's
+// initialization: the package-level variable declarations, an init function, or a closure in
+// either.
+func initializer(fn string) bool {
+	pkg := funcPackage(fn)
+	if len(pkg) >= len(fn) {
+		return false
+	}
+	name := fn[len(pkg)+1:]
+	return "init" == name || strings.HasPrefix(name, "init.")
+}
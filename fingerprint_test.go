@@ -0,0 +1,116 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// Sentinels declared together share their origin, the package initializer, and this file.
+var (
+	errFirst  = errors.New("first")
+	errSecond = errors.New("second")
+)
+
+func lookupUser(id string) error {
+	return errors.Wrap(io.ErrUnexpectedEOF, "reading user %s", id).WithCode("user_read")
+}
+
+func lookupOrder(id string) error {
+	return errors.Wrap(io.ErrUnexpectedEOF, "reading order %s", id).WithCode("user_read")
+}
+
+func TestFingerprint(t *testing.T) {
+	first := errors.Wrap(lookupUser("u-1"), "handling request")
+	second := errors.Wrap(lookupUser("u-2"), "handling request")
+	if errors.Fingerprint(first) != errors.Fingerprint(second) {
+		t.Error("the interpolated values changed the fingerprint")
+	}
+	if 32 != len(errors.Fingerprint(first)) {
+		t.Errorf("Fingerprint = %q, want 32 hex digits", errors.Fingerprint(first))
+	}
+
+	for name, other := range map[string]error{
+		"another function": errors.Wrap(lookupOrder("u-1"), "handling request"),
+		"another code":     errors.Wrap(lookupUser("u-1"), "handling request").WithCode("other"),
+		"another kind":     errors.Wrap(lookupUser("u-1"), "handling request").WithKind(errors.KindNotFound),
+		"another sentinel": errors.Wrap(errors.Wrap(io.EOF, "reading user").WithCode("user_read"), "handling request"),
+		"another length":   errors.Wrap(errors.Trace(lookupUser("u-1")), "handling request"),
+		"a join":           errors.Join(first, second),
+	} {
+		if errors.Fingerprint(first) == errors.Fingerprint(other) {
+			t.Errorf("%s: the fingerprint did not change", name)
+		}
+	}
+
+	// Lines are not part of it: the same function, twice.
+	pair := []error{}
+	for i := 0; i < 2; i++ {
+		pair = append(pair, errors.New(fmt.Sprintf("attempt %d", i)))
+	}
+	a, b := pair[0], errors.New("another line")
+	if errors.Fingerprint(pair[0]) != errors.Fingerprint(pair[1]) || errors.Fingerprint(a) != errors.Fingerprint(b) {
+		t.Error("the line or message changed the fingerprint")
+	}
+
+	if "" != errors.Fingerprint(nil) {
+		t.Error("a nil error has a fingerprint")
+	}
+	if errors.Fingerprint(std_errors.New("x")) == errors.Fingerprint(std_errors.New("y")) {
+		t.Error("foreign sentinels with different messages share a fingerprint")
+	}
+}
+
+func TestFingerprintCoversSentinelsAndAnnotations(t *testing.T) {
+	cause := errors.New("cause")
+	for name, pair := range map[string][2]error{
+		"wrapped sentinels":           {errors.Wrap(errFirst, "x"), errors.Wrap(errSecond, "x")},
+		"sentinel annotations":        {errors.WrapE(cause, errFirst), errors.WrapE(cause, errSecond)},
+		"foreign annotations":         {errors.WrapE(cause, io.EOF), errors.WrapE(cause, io.ErrUnexpectedEOF)},
+		"nested sentinel annotations": {errors.WrapE(cause, errors.Wrap(errFirst, "x")), errors.WrapE(cause, errors.Wrap(errSecond, "x"))},
+	} {
+		if errors.Fingerprint(pair[0]) == errors.Fingerprint(pair[1]) {
+			t.Errorf("%s share a fingerprint", name)
+		}
+	}
+	if errors.Fingerprint(errors.Wrap(errFirst, "user %d", 1)) != errors.Fingerprint(errors.Wrap(errFirst, "user %d", 2)) {
+		t.Error("the interpolated values of a Wrap changed the fingerprint")
+	}
+}
+
+func TestFingerprintInJSON(t *testing.T) {
+	err := errors.Wrap(lookupUser("u-1"), "handling request")
+
+	var frames []map[string]interface{}
+	byts, _ := json.Marshal(err)
+	if jsonErr := json.Unmarshal(byts, &frames); nil != jsonErr {
+		t.Fatalf("%s: %v", byts, jsonErr)
+	}
+	if errors.Fingerprint(err) != frames[0]["fingerprint"] {
+		t.Errorf("MarshalJSON = %s, want the fingerprint on the outermost frame", byts)
+	}
+	for _, frame := range frames[1:] {
+		if _, ok := frame["fingerprint"]; ok {
+			t.Errorf("MarshalJSON = %s, want the fingerprint once", byts)
+		}
+	}
+	if verb := fmt.Sprintf("%#+v", err); string(byts) != verb {
+		t.Errorf("%%#+v = %s, want %s", verb, byts)
+	}
+
+	joined := errors.Join(err, errors.New("x"))
+	if n := strings.Count(fmt.Sprintf("%#+v", joined), `"fingerprint"`); 1 != n {
+		t.Errorf("%%#+v of a join has %d fingerprints, want 1: %#+v", n, joined)
+	}
+
+	errors.SetJSONFormat(errors.JSONFormat{OmitFingerprint: true})
+	defer errors.SetJSONFormat(errors.JSONFormat{})
+	if strings.Contains(fmt.Sprintf("%#+v", err), `"fingerprint"`) {
+		t.Errorf("OmitFingerprint left a fingerprint: %#+v", err)
+	}
+}
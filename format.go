@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
-	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +23,17 @@ import (
 //	+      Output full error stack details, useful for debugging
 //	' '    (space) Add whitespace formatting for readability, useful for development
 //
+// The JSON forms add a "code" member to any frame carrying a code, see WithCode, a "kind" member to
+// any frame carrying a kind, see WithKind, and a "fields" member to any frame carrying fields, see
+// WithFields. Their shape is the one set with SetJSONFormat; the examples below are JSONSchemaV1.
+// Their messages and fields are redacted by the policy set with SetRedactionPolicy, as the other
+// forms' messages are if the policy's Text is set.
+//
+// The trace forms render the whole error tree. The branches of an error with several causes -- Join,
+// errors.Join, fmt.Errorf with more than one %w -- follow the frame they belong to, numbered within
+// it, so "#1.0.2" is the third frame of the first branch of frame 1, and "% +v" indents each branch a
+// level further. In JSON they are a "causes" member of that frame, an array of arrays of frames.
+//
 // Examples:
 //
 //	%s:    An error occurred
@@ -37,7 +48,7 @@ func (e *E) Format(state fmt.State, verb rune) {
 
 	switch verb {
 	default:
-		fmt.Fprint(str, e.Error())
+		fmt.Fprint(str, redactionPolicy().text(e.Error()))
 
 	case 'v':
 		var (
@@ -45,9 +56,6 @@ func (e *E) Format(state fmt.State, verb rune) {
 			flagFormat bool
 			flagTrace  bool
 			modeJSON   bool
-			lastE      error
-			key        int
-			nextE      error
 		)
 
 		if state.Flag('#') {
@@ -64,28 +72,33 @@ func (e *E) Format(state fmt.State, verb rune) {
 		}
 
 		jsonData := []map[string]interface{}{}
-		sp := ""
-
-		for key, nextE = range list(e) {
-			sp, jsonData, str = format(key, nextE, sp, jsonData, str, flagDetail, flagFormat, flagTrace, modeJSON)
-			if !flagTrace {
-				break
-			}
-
-			if !flagDetail &&
-				!flagFormat &&
-				!flagTrace &&
-				!modeJSON {
-				break
+		links := traceLinks(e)
+
+		switch {
+		case flagTrace && modeJSON:
+			jsonData = chainJSON(e, jsonFormat())
+
+		case flagTrace:
+			sp := ""
+			formatTree(str, &sp, links, "", 0, flagFormat)
+
+		default:
+			// One frame. A frame with no message of its own -- Trace, Join -- has nothing to show
+			// when only messages are shown, so the first frame beneath it that has one stands in
+			// for it.
+			key := 0
+			if !flagDetail {
+				for key < len(links)-1 && "" == frameMessage(links[key]) {
+					key++
+				}
 			}
-
-			if err, ok := nextE.(*E); ok {
-				lastE = err.prev
+			if modeJSON {
+				jsonData = append(jsonData, frameJSON(key, links[key], flagDetail, jsonFormat()))
+			} else {
+				formatFrame(str, "", strconv.Itoa(key), links[key], flagDetail, flagFormat)
 			}
 		}
-		if nil != lastE {
-			_, jsonData, str = format(key+1, lastE, sp, jsonData, str, flagDetail, flagFormat, flagTrace, modeJSON)
-		}
+
 		if modeJSON {
 			var byts []byte
 			if flagFormat {
@@ -101,61 +114,70 @@ func (e *E) Format(state fmt.State, verb rune) {
 	fmt.Fprintf(state, "%s", strings.Trim(str.String(), "\r\n\t"))
 }
 
-func format(key int, nextE error, sp string, jsonData []map[string]interface{}, str *bytes.Buffer, flagDetail bool, flagFormat bool, flagTrace bool, modeJSON bool) (string, []map[string]interface{}, *bytes.Buffer) {
+// formatTree writes the frames of links as the %+v verbs render them, and the branches of the error
+// at its end, if it has several causes, beneath the frame they belong to. A branch's frames are
+// numbered within it, after the frame it belongs to -- "#1.0.2" is the third frame of the first
+// branch of frame 1 -- and the "% +v" form indents them a level further.
+func formatTree(str *bytes.Buffer, sp *string, links []error, prefix string, depth int, flagFormat bool) {
+	links, branches := treeLinks(links)
+	indent := ""
+	if flagFormat {
+		indent = strings.Repeat("    ", depth)
+	}
+	for key, link := range links {
+		formatFrame(str, *sp+indent, prefix+strconv.Itoa(key), link, true, flagFormat)
+		if !flagFormat {
+			*sp = " "
+		}
+	}
+	for i, branch := range branches {
+		formatTree(str, sp, traceLinks(branch), fmt.Sprintf("%s%d.%d.", prefix, len(links)-1, i), depth+1, flagFormat)
+	}
+}
+
+// formatFrame writes one frame of the text formats: its message, and with withCaller, where it was
+// created. sp precedes the frame.
+func formatFrame(str *bytes.Buffer, sp, key string, nextE error, withCaller bool, flagFormat bool) {
 	err, ok := nextE.(*E)
+	msg := redactionPolicy().text(frameMessage(nextE))
 
-	if modeJSON {
-		data := map[string]interface{}{}
-		if flagDetail || flagTrace {
-			if ok && nil != err.Caller() {
-				data["caller"] = fmt.Sprintf("#%d %s:%d (%s)",
-					key,
-					path.Base(err.Caller().File()),
-					err.Caller().Line(),
-					runtime.FuncForPC(err.Caller().Pc()).Name(),
-				)
-			} else {
-				data["caller"] = fmt.Sprintf("#%d n/a",
-					key,
-				)
-			}
-		}
-		if "" != frameMessage(nextE) {
-			data["error"] = frameMessage(nextE)
-		}
-		jsonData = append(jsonData, data)
+	if "" != msg {
+		fmt.Fprintf(str, "%s%s", sp, msg)
+	} else if flagFormat {
+		fmt.Fprint(str, sp)
+	}
 
-	} else {
-		if "" != frameMessage(nextE) {
-			fmt.Fprintf(str, "%s%s", sp, frameMessage(nextE))
+	if withCaller {
+		if "" != msg {
+			fmt.Fprintf(str, " - ")
 		}
-
-		if flagDetail || flagTrace {
-			if "" != frameMessage(nextE) {
-				fmt.Fprintf(str, " - ")
+		if ok && nil != err.Caller() {
+			fmt.Fprintf(str, "#%s %s:%d (%s)",
+				key,
+				path.Base(err.Caller().File()),
+				err.Caller().Line(),
+				err.Caller().Func(),
+			)
+			if "" != err.id {
+				fmt.Fprintf(str, " id=%s", err.id)
 			}
-			if ok && nil != err.Caller() {
-				fmt.Fprintf(str, "#%d %s:%d (%s);",
-					key,
-					path.Base(err.Caller().File()),
-					err.Caller().Line(),
-					runtime.FuncForPC(err.Caller().Pc()).Name(),
-				)
-			} else {
-				fmt.Fprintf(str, "#%d n/a",
-					key,
-				)
+			fmt.Fprint(str, ";")
+		} else {
+			fmt.Fprintf(str, "#%s n/a",
+				key,
+			)
+			if ok && "" != err.id {
+				fmt.Fprintf(str, " id=%s", err.id)
 			}
 		}
+	}
 
-		if flagFormat {
-			str = bytes.NewBuffer([]byte(strings.Trim(str.String(), " ")))
-			fmt.Fprintf(str, "\n")
-		} else if flagTrace {
-			sp = " "
-		}
+	if flagFormat {
+		trimmed := strings.Trim(str.String(), " ")
+		str.Reset()
+		str.WriteString(trimmed)
+		fmt.Fprintf(str, "\n")
 	}
-	return sp, jsonData, str
 }
 
 // frameMessage is one link's own message. For an *E that is its frame message without the wrapped
@@ -170,3 +192,57 @@ func frameMessage(err error) string {
 	}
 	return err.Error()
 }
+
+// traceLinks returns the links of err's chain as the trace formats render them, outermost first:
+// each error along the single-error Unwrap chain, ending with the first error that does not wrap
+// another one, or wraps several.
+func traceLinks(err error) []error {
+	links := list(err)
+	if 0 == len(links) {
+		return []error{err}
+	}
+	if e, ok := links[len(links)-1].(*E); ok && nil != e.prev {
+		links = append(links, e.prev)
+	}
+	return links
+}
+
+// treeLinks splits the branches off the error at the end of links, if it wraps several.
+//
+// Those of a bare join -- one made by Join or errors.Join, whose message is nothing but the branches'
+// -- belong to the link before it, which for Join is the *E carrying the caller data: the join itself
+// is not a frame. A bare join with no link before it is a frame with neither message nor caller. The
+// branches of any other error, such as a fmt.Errorf with several %w verbs, are its own.
+func treeLinks(links []error) ([]error, []error) {
+	last := links[len(links)-1]
+	multi, ok := last.(interface{ Unwrap() []error })
+	if !ok {
+		return links, nil
+	}
+	branches := []error{}
+	for _, branch := range multi.Unwrap() {
+		if nil != branch {
+			branches = append(branches, branch)
+		}
+	}
+	if bareJoin(last, branches) {
+		if 1 < len(links) {
+			return links[:len(links)-1], branches
+		}
+		return []error{&E{}}, branches
+	}
+	return links, branches
+}
+
+// bareJoin reports whether err, which wraps branches, has no message of its own: it is a join made by
+// this package, or its message is the branches' separated by newlines, as errors.Join has it.
+func bareJoin(err error, branches []error) bool {
+	if _, ok := err.(*joined); ok {
+		return true
+	}
+	msgs := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		msgs = append(msgs, branch.Error())
+	}
+	return err.Error() == strings.Join(msgs, "\n")
+}
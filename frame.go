@@ -0,0 +1,136 @@
+package errors
+
+// Frame is a snapshot of one link of an error chain, in a form that can cross a process boundary.
+//
+// An error value cannot leave the process that created it: its caller data is a program counter,
+// meaningful only to the binary that produced it, and its sentinels are compared by identity. A
+// Frame carries what survives instead -- the message, where it was created, and the structured data
+// attached to it -- so that transports such as the grpc subpackage can send a chain and the
+// receiving side can rebuild an equivalent one with FromFrames.
+type Frame struct {
+	// Message is the link's own message, without the messages of the errors it wraps.
+	Message string
+	// File, Line and Func locate where the link was created. They are empty for an error that
+	// carries no caller data.
+	File string
+	Line int
+	Func string
+	// Code, Kind and Fields are the link's own, see WithCode, WithKind and WithFields. Kind is the
+	// kind's name.
+	Code   Code
+	Kind   string
+	Fields map[string]interface{}
+	// Public is the link's own public message, see WithPublic.
+	Public string
+	// ID is the link's own instance ID, see WithID.
+	ID string
+	// Remote reports whether the link was itself received from another process.
+	Remote bool
+}
+
+// Frames returns a snapshot of each link of err's chain, outermost first.
+//
+// The chain is the sequence Unwrap yields. A foreign error type is included as a frame of its own
+// with only its message, since that is all it is known to have.
+func Frames(err error) []Frame {
+	frames := []Frame{}
+	for nil != err {
+		frame := Frame{
+			Message: frameMessage(err),
+		}
+		if e, ok := err.(*E); ok && nil != e {
+			if clr := e.Caller(); nil != clr {
+				frame.File = clr.File()
+				frame.Line = clr.Line()
+				frame.Func = clr.Func()
+			}
+			frame.Code = e.code
+			frame.Kind = e.kind.Name()
+			if 0 < len(e.fields) {
+				frame.Fields = make(map[string]interface{}, len(e.fields))
+				for k, v := range e.fields {
+					frame.Fields[k] = v
+				}
+			}
+			frame.Public = e.public
+			frame.ID = e.id
+			frame.Remote = e.remote
+		}
+		frames = append(frames, frame)
+		err = Unwrap(err)
+	}
+	return frames
+}
+
+// FromFrames rebuilds an error chain from frames, outermost first, as produced by Frames. It returns
+// nil if frames is empty.
+//
+// A frame with no caller data is taken to be a foreign error, as Frames records one, and its
+// message to be that error's whole Error() text.
+//
+// Every link of the result is an *E marked as remote: its Error, format and JSON output match the
+// original's, and Code, Kind and Fields lookups work as they did there, but its caller data
+// describes the process that sent it. A kind is resolved by name with LookupKind, so Is matches it
+// only if this process defines the same kind.
+func FromFrames(frames []Frame) *E {
+	var top *E
+	for i := len(frames) - 1; 0 <= i; i-- {
+		frame := frames[i]
+		e := &E{
+			code:   frame.Code,
+			kind:   LookupKind(frame.Kind),
+			public: frame.Public,
+			id:     frame.ID,
+			remote: true,
+		}
+		// Only assigned when there is one: a nil *E stored in the interface is not a nil error.
+		if nil != top {
+			e.prev = top
+		}
+		if "" != frame.Message {
+			// A link with no caller data was not an *E, and a foreign error's message is its whole
+			// Error() text: fmt.Errorf("%w") includes the cause, a custom wrapper may not. Either
+			// way, appending the rebuilt tail to it again would not reproduce the original.
+			e.err = &remoteError{
+				msg:   frame.Message,
+				whole: "" == frame.File && "" == frame.Func,
+			}
+		}
+		if "" != frame.File || "" != frame.Func {
+			e.caller = &remoteCaller{
+				file: frame.File,
+				fn:   frame.Func,
+				line: frame.Line,
+			}
+		}
+		if 0 < len(frame.Fields) {
+			e.fields = make(map[string]interface{}, len(frame.Fields))
+			for k, v := range frame.Fields {
+				e.fields[k] = v
+			}
+		}
+		top = e
+	}
+	return top
+}
+
+// IsRemote reports whether the outermost link of err was received from another process, see
+// FromFrames.
+func IsRemote(err error) bool {
+	e, ok := err.(*E)
+	return ok && nil != e && e.remote
+}
+
+// remoteError is the message of a frame received from another process. Its identity, unlike its
+// text, did not survive the trip, so it is deliberately a type of its own rather than an
+// errors.New value that might be mistaken for a local sentinel.
+type remoteError struct {
+	msg string
+	// whole reports that msg is the Error() text of the whole chain from this link down, as it is
+	// for a link that was a foreign error rather than an *E.
+	whole bool
+}
+
+func (err *remoteError) Error() string {
+	return err.msg
+}
@@ -0,0 +1,82 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestFramesSnapshotsEachLink(t *testing.T) {
+	err := errors.Wrap(
+		fmt.Errorf("fmt layer: %w", errors.New("inner").WithCode("c").WithKind(errors.KindNotFound)),
+		"outer",
+	).WithField("k", "v")
+
+	frames := errors.Frames(err)
+	if 3 != len(frames) {
+		t.Fatalf("frames = %d, want 3: %+v", len(frames), frames)
+	}
+	if "outer" != frames[0].Message || "v" != frames[0].Fields["k"] || "" == frames[0].File || 0 == frames[0].Line {
+		t.Errorf("outer frame = %+v", frames[0])
+	}
+	if "" != frames[1].File || !strings.HasPrefix(frames[1].Message, "fmt layer") {
+		t.Errorf("a foreign link should carry only its message: %+v", frames[1])
+	}
+	if "inner" != frames[2].Message || "c" != frames[2].Code || "not_found" != frames[2].Kind {
+		t.Errorf("inner frame = %+v", frames[2])
+	}
+	if 0 != len(errors.Frames(nil)) {
+		t.Error("a nil error has frames")
+	}
+}
+
+func TestFromFramesRebuildsAnEquivalentChain(t *testing.T) {
+	original := errors.Wrap(errors.Wrap(errors.New("inner").WithKind(errors.KindNotFound), "middle"), "outer")
+	rebuilt := errors.FromFrames(errors.Frames(original))
+
+	if original.Error() != rebuilt.Error() {
+		t.Errorf("Error() = %q, want %q", rebuilt.Error(), original.Error())
+	}
+	for _, verb := range []string{"%+v", "%#+v"} {
+		want := fmt.Sprintf(verb, original)
+		got := fmt.Sprintf(verb, rebuilt)
+		if "%#+v" == verb {
+			got = strings.Replace(got, `,"remote":true`, "", -1)
+		}
+		if want != got {
+			t.Errorf("%s differs:\n got: %s\nwant: %s", verb, got, want)
+		}
+	}
+	if !errors.IsRemote(rebuilt) || errors.IsRemote(original) {
+		t.Error("remote marking is wrong")
+	}
+	if !errors.Is(rebuilt, errors.KindNotFound) {
+		t.Error("the kind was not resolved by name")
+	}
+
+	raw, _ := json.Marshal(rebuilt)
+	if !strings.Contains(string(raw), `"remote":true`) {
+		t.Errorf("MarshalJSON does not mark remote frames: %s", raw)
+	}
+	if nil != errors.FromFrames(nil) {
+		t.Error("no frames should rebuild to nil")
+	}
+}
+
+func TestFromFramesKeepsAForeignLinkWhole(t *testing.T) {
+	original := errors.Wrap(
+		fmt.Errorf("fmt layer: %w", errors.New("inner").WithCode("c")),
+		"outer",
+	)
+	rebuilt := errors.FromFrames(errors.Frames(original))
+
+	if original.Error() != rebuilt.Error() {
+		t.Errorf("Error() = %q, want %q", rebuilt.Error(), original.Error())
+	}
+	if "c" != errors.CodeOf(rebuilt) {
+		t.Errorf("CodeOf = %q, want the code behind the foreign link", errors.CodeOf(rebuilt))
+	}
+}
@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/bdlm/std/v2 v2.1.0
+	github.com/golang/protobuf v1.3.3
 	github.com/stretchr/testify v1.6.1
 	google.golang.org/grpc v1.29.1
 )
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions in goroutines and collects their errors, as golang.org/x/sync/errgroup
+// does, with the caller data this package records.
+//
+// Every error a goroutine returns is wrapped in a frame recording where Go was called, so that a
+// trace shows which Go call it came from as well as where it failed. A panic is recovered with
+// FromPanic rather than crashing the process. Wait returns all of the errors, joined, not just the
+// first.
+//
+// A zero Group is valid, has no limit on active goroutines, and does not cancel anything on
+// failure. A Group must not be copied after first use.
+type Group struct {
+	cancel func()
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a Group and a context derived from ctx, which is canceled the first time a
+// function passed to Go fails, or when Wait returns, whichever happens first.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of goroutines the Group runs at once to n. Go then blocks until one of
+// them returns. A negative n is no limit, which is the default.
+//
+// The limit must not be changed while any goroutine in the Group is active.
+func (g *Group) SetLimit(n int) {
+	if 0 > n {
+		g.sem = nil
+		return
+	}
+	if 0 != len(g.sem) {
+		panic("errors: SetLimit called while goroutines are active")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls fn in a new goroutine, blocking first while the Group is at its limit, see SetLimit.
+//
+// If fn returns an error or panics, the error is recorded for Wait, wrapped in a frame whose caller
+// is where Go was called, and the Group's context is canceled.
+func (g *Group) Go(fn func() error) {
+	spawn := newCaller(3)
+	sem := g.sem
+	if nil != sem {
+		sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); nil != r {
+				err = FromPanic(r)
+			}
+			if nil != err {
+				g.fail(&E{
+					caller: spawn,
+					prev:   err,
+				})
+			}
+			if nil != sem {
+				<-sem
+			}
+			g.wg.Done()
+		}()
+		err = fn()
+	}()
+}
+
+// fail records err and cancels the Group's context.
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+	if nil != g.cancel {
+		g.cancel()
+	}
+}
+
+// Wait blocks until every function passed to Go has returned, and returns a join of their errors,
+// in the order they failed, recorded at the call to Wait, or nil if none failed. See Join. The
+// result is an error rather than an *E, as errgroup's is, so that returning it from a function that
+// returns error returns a nil error when nothing failed; the join is an *E all the same.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if nil != g.cancel {
+		g.cancel()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if 0 == len(g.errs) {
+		return nil
+	}
+	return join(g.errs)
+}
@@ -0,0 +1,113 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func failAt(msg string) error {
+	return errors.New(msg)
+}
+
+func TestGroupJoinsEveryFailure(t *testing.T) {
+	g, ctx := errors.NewGroup(context.Background())
+	g.Go(func() error { return failAt("first") })
+	g.Go(func() error { return nil })
+	g.Go(func() error { <-ctx.Done(); return failAt("second") })
+	err := g.Wait()
+
+	if nil == err {
+		t.Fatal("Wait = nil, want both failures")
+	}
+	for _, msg := range []string{"first", "second"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), msg)
+		}
+	}
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestGroupJoinsEveryFailure") {
+		t.Errorf("caller is %s, want the call to Wait", errors.Caller(err).Func())
+	}
+	if nil == ctx.Err() {
+		t.Error("the context was not canceled")
+	}
+}
+
+// waitFor runs fns in a Group and returns Wait's result as an error, the way a caller usually does.
+func waitFor(fns ...func() error) error {
+	var g errors.Group
+	for _, fn := range fns {
+		g.Go(fn)
+	}
+	return g.Wait()
+}
+
+func TestGroupWaitReturnsANilError(t *testing.T) {
+	ok := func() error { return nil }
+	if err := waitFor(ok, ok); nil != err {
+		t.Errorf("Wait = %#v when nothing failed, want a nil error", err)
+	}
+	if err := waitFor(ok, func() error { return failAt("failed") }); nil == err {
+		t.Error("Wait = nil after a failure")
+	} else if _, isE := err.(*errors.E); !isE {
+		t.Errorf("Wait = %T after a failure, want an *errors.E", err)
+	}
+}
+
+func TestGroupRecordsTheSpawnSite(t *testing.T) {
+	var g errors.Group
+	g.Go(func() error { return failAt("failed") })
+	spawnLine := errors.Caller(errors.New("")).Line() - 1
+	err := g.Wait()
+
+	trace := fmt.Sprintf("%+v", err)
+	spawn := fmt.Sprintf("#0.0.0 group_test.go:%d (", spawnLine)
+	failure := fmt.Sprintf("failed - #0.0.1 group_test.go:%d (github.com/bdlm/errors/v2_test.failAt);",
+		errors.Caller(failAt("")).Line())
+	if !strings.Contains(trace, spawn) || !strings.Contains(trace, failure) {
+		t.Errorf("%%+v = %s\nwant the spawn site %q and the failure %q", trace, spawn, failure)
+	}
+}
+
+func TestGroupRecoversPanics(t *testing.T) {
+	var g errors.Group
+	g.Go(func() error { panicWith("boom"); return nil })
+	err := g.Wait()
+	if !errors.Is(err, errors.KindPanic) || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("Wait = %+v, want the recovered panic", err)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), ".panicWith);") {
+		t.Errorf("the panic site is not in the trace: %+v", err)
+	}
+}
+
+func TestGroupSetLimit(t *testing.T) {
+	var g errors.Group
+	g.SetLimit(2)
+	var active, most int32
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&most)
+				if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); nil != err {
+		t.Errorf("Wait = %v", err)
+	}
+	if 2 < most {
+		t.Errorf("%d goroutines ran at once, want at most 2", most)
+	}
+}
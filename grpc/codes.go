@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// kindCodes maps each canonical kind to the status code of the same meaning. The canonical kinds
+// were chosen to mirror the status codes, so the mapping is one to one.
+var kindCodes = map[*errors.Kind]codes.Code{
+	errors.KindCanceled:           codes.Canceled,
+	errors.KindUnknown:            codes.Unknown,
+	errors.KindInvalidArgument:    codes.InvalidArgument,
+	errors.KindDeadlineExceeded:   codes.DeadlineExceeded,
+	errors.KindNotFound:           codes.NotFound,
+	errors.KindAlreadyExists:      codes.AlreadyExists,
+	errors.KindPermissionDenied:   codes.PermissionDenied,
+	errors.KindResourceExhausted:  codes.ResourceExhausted,
+	errors.KindFailedPrecondition: codes.FailedPrecondition,
+	errors.KindAborted:            codes.Aborted,
+	errors.KindOutOfRange:         codes.OutOfRange,
+	errors.KindUnimplemented:      codes.Unimplemented,
+	errors.KindInternal:           codes.Internal,
+	errors.KindUnavailable:        codes.Unavailable,
+	errors.KindDataLoss:           codes.DataLoss,
+	errors.KindUnauthenticated:    codes.Unauthenticated,
+}
+
+// codeKinds is kindCodes inverted.
+var codeKinds = func() map[codes.Code]*errors.Kind {
+	ret := make(map[codes.Code]*errors.Kind, len(kindCodes))
+	for kind, code := range kindCodes {
+		ret[code] = kind
+	}
+	return ret
+}()
+
+func init() {
+	errors.RegisterRetryClassifier(retryable)
+}
+
+// retryable classifies a status error for errors.IsRetryable: codes.Unavailable is retryable, which
+// is what the status code means. Every other code is left to the rest of the chain.
+func retryable(err error) (retryable, ok bool) {
+	st, isStatus := err.(interface{ GRPCStatus() *status.Status })
+	if !isStatus || nil == st.GRPCStatus() {
+		return false, false
+	}
+	if codes.Unavailable == st.GRPCStatus().Code() {
+		return true, true
+	}
+	return false, false
+}
+
+// Code returns the status code for err.
+//
+// In order of precedence, it is derived from:
+//
+//   - the nearest kind in the chain, see errors.KindOf. A kind that is not canonical maps to the
+//     code of its nearest canonical ancestor.
+//   - the nearest error in the chain implementing GRPCStatus() *status.Status, such as one returned
+//     by status.Error or received from a downstream call.
+//   - context.Canceled and context.DeadlineExceeded anywhere in the chain.
+//
+// A nil error is codes.OK, and anything else is codes.Unknown.
+func Code(err error) codes.Code {
+	if nil == err {
+		return codes.OK
+	}
+	for kind := errors.KindOf(err); nil != kind; kind = kind.Parent() {
+		if code, ok := kindCodes[kind]; ok {
+			return code
+		}
+	}
+	var st interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &st) && nil != st.GRPCStatus() {
+		return st.GRPCStatus().Code()
+	}
+	if errors.Is(err, context.Canceled) {
+		return codes.Canceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+	return codes.Unknown
+}
+
+// Kind returns the canonical kind for a status code, or nil for codes.OK.
+func Kind(code codes.Code) *errors.Kind {
+	if kind, ok := codeKinds[code]; ok {
+		return kind
+	}
+	if codes.OK == code {
+		return nil
+	}
+	return errors.KindUnknown
+}
@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	google_grpc "google.golang.org/grpc"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// Logger receives every error a server interceptor returns to a client, before it is converted to
+// a status, so the full chain -- which the status deliberately does not carry in readable form --
+// is recorded once, on the server, where it is useful.
+type Logger func(ctx context.Context, method string, err error)
+
+// Option configures the interceptors.
+type Option func(*options)
+
+type options struct {
+	logger Logger
+	view   errors.View
+}
+
+// WithLogger sets the Logger a server interceptor reports errors to. The default writes the %+v
+// trace to the standard library's log package, redacted by the policy set with
+// errors.SetRedactionPolicy; a nil Logger disables logging.
+func WithLogger(logger Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithView sets the view of an error a server interceptor shows its clients, see ToStatusView. The
+// default is errors.PublicView, which shows nothing internal. A server whose clients are peer
+// services, which rebuild the whole chain with FromError, opts in to errors.InternalView.
+func WithView(view errors.View) Option {
+	return func(opts *options) {
+		opts.view = view
+	}
+}
+
+func newOptions(opts []Option) *options {
+	ret := &options{
+		logger: func(ctx context.Context, method string, err error) {
+			log.Print(errors.RedactMessage(fmt.Sprintf("%s: %+v", method, err)))
+		},
+		view: errors.PublicView,
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+// UnaryServerInterceptor returns a server interceptor that converts the error a handler returns
+// to a status, see ToStatusView, after reporting it to the Logger. A panic in the handler is recovered
+// with errors.FromPanic, into an error of kind errors.KindPanic whose trace starts at the panic,
+// and handled the same way: its status code is codes.Internal.
+func UnaryServerInterceptor(opts ...Option) google_grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *google_grpc.UnaryServerInfo,
+		handler google_grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); nil != r {
+				err = errors.FromPanic(r)
+			}
+			err = o.convert(ctx, info.FullMethod, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(opts ...Option) google_grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(
+		srv interface{},
+		stream google_grpc.ServerStream,
+		info *google_grpc.StreamServerInfo,
+		handler google_grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); nil != r {
+				err = errors.FromPanic(r)
+			}
+			err = o.convert(stream.Context(), info.FullMethod, err)
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that converts a status error received from
+// the server back to an *errors.E chain, see FromError, so Is, As and the lookup functions work on
+// it as they did on the server.
+func UnaryClientInterceptor() google_grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *google_grpc.ClientConn,
+		invoker google_grpc.UnaryInvoker,
+		opts ...google_grpc.CallOption,
+	) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor. It converts the
+// error establishing the stream, and every error the stream itself returns.
+func StreamClientInterceptor() google_grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *google_grpc.StreamDesc,
+		cc *google_grpc.ClientConn,
+		method string,
+		streamer google_grpc.Streamer,
+		opts ...google_grpc.CallOption,
+	) (google_grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if nil != err {
+			return nil, FromError(err)
+		}
+		return &clientStream{ClientStream: stream}, nil
+	}
+}
+
+// clientStream converts the errors of the stream it wraps. io.EOF, which ends every stream, is not
+// a status and so passes through unchanged.
+type clientStream struct {
+	google_grpc.ClientStream
+}
+
+func (s *clientStream) CloseSend() error {
+	return FromError(s.ClientStream.CloseSend())
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return FromError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return FromError(s.ClientStream.SendMsg(m))
+}
+
+// convert reports err and returns it as a status error. It is a no-op for nil.
+func (o *options) convert(ctx context.Context, method string, err error) error {
+	if nil == err {
+		return nil
+	}
+	if nil != o.logger {
+		o.logger(ctx, method, err)
+	}
+	return ToStatusView(err, o.view).Err()
+}
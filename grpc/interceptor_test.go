@@ -0,0 +1,225 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bdlm/errors/v2"
+	errors_grpc "github.com/bdlm/errors/v2/grpc"
+)
+
+var unaryInfo = &google_grpc.UnaryServerInfo{FullMethod: "/test.Service/Unary"}
+
+// recordingLogger collects what the interceptors report.
+type recordingLogger struct {
+	errs    []error
+	methods []string
+	traces  []string
+}
+
+func (l *recordingLogger) log(ctx context.Context, method string, err error) {
+	l.errs = append(l.errs, err)
+	l.methods = append(l.methods, method)
+	l.traces = append(l.traces, fmt.Sprintf("%+v", err))
+}
+
+func TestUnaryServerInterceptorConvertsAndLogs(t *testing.T) {
+	logger := &recordingLogger{}
+	interceptor := errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(logger.log), errors_grpc.WithView(errors.InternalView))
+
+	handlerErr := errors.Wrap(errors.New("no row"), "account lookup failed").WithKind(errors.KindNotFound)
+	_, err := interceptor(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		return nil, handlerErr
+	})
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("returned %T, want a status error", err)
+	}
+	if codes.NotFound != st.Code() || "account lookup failed" != st.Message() {
+		t.Errorf("status = %s %q", st.Code(), st.Message())
+	}
+	if 1 != len(logger.traces) || unaryInfo.FullMethod != logger.methods[0] {
+		t.Fatalf("logged %d times, want once: %v", len(logger.traces), logger.methods)
+	}
+	if want := fmt.Sprintf("%+v", handlerErr); want != logger.traces[0] {
+		t.Errorf("logged %q, want the full trace %q", logger.traces[0], want)
+	}
+}
+
+func TestUnaryServerInterceptorRedactsTheDefaultLog(t *testing.T) {
+	var logged bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	interceptor := errors_grpc.UnaryServerInterceptor()
+	_, _ = interceptor(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.Wrap(errors.New("no account for jane@example.com"), "charging card 4111 1111 1111 1111")
+	})
+	if !strings.Contains(logged.String(), unaryInfo.FullMethod+": ") || !strings.Contains(logged.String(), errors.Redacted) {
+		t.Errorf("logged %q, want the redacted trace", logged.String())
+	}
+	for _, leak := range []string{"jane@example.com", "4111 1111 1111 1111"} {
+		if strings.Contains(logged.String(), leak) {
+			t.Errorf("the default log leaks %q: %s", leak, logged.String())
+		}
+	}
+}
+
+func TestUnaryServerInterceptorPassesSuccessThrough(t *testing.T) {
+	logger := &recordingLogger{}
+	interceptor := errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(logger.log))
+	resp, err := interceptor(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	if nil != err || "ok" != resp || 0 != len(logger.traces) {
+		t.Errorf("resp = %v, err = %v, logged %d", resp, err, len(logger.traces))
+	}
+}
+
+func panickingHandler(context.Context, interface{}) (interface{}, error) {
+	panic("nil map write")
+}
+
+func TestUnaryServerInterceptorRecoversPanics(t *testing.T) {
+	logger := &recordingLogger{}
+	interceptor := errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(logger.log))
+
+	_, err := interceptor(context.Background(), nil, unaryInfo, panickingHandler)
+	if codes.Internal != status.Code(err) {
+		t.Errorf("code = %s, want Internal", status.Code(err))
+	}
+	if 1 != len(logger.traces) {
+		t.Fatalf("logged %d times, want once", len(logger.traces))
+	}
+	if !strings.Contains(logger.traces[0], "nil map write") {
+		t.Errorf("the panic value is not in the trace: %s", logger.traces[0])
+	}
+
+	if clr := errors.Caller(logger.errs[0]); !strings.HasSuffix(clr.Func(), ".panickingHandler") {
+		t.Errorf("the trace does not start at the panic: %+v", logger.errs[0])
+	}
+}
+
+func TestUnaryServerInterceptorKeepsAPanickedError(t *testing.T) {
+	interceptor := errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(nil), errors_grpc.WithView(errors.InternalView))
+	_, err := interceptor(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		panic(errors.New("invariant broken").WithCode("invariant"))
+	})
+	if "invariant" != errors.CodeOf(errors_grpc.FromError(err)) {
+		t.Errorf("the panicked error's code was lost: %v", err)
+	}
+}
+
+type serverStream struct {
+	google_grpc.ServerStream
+}
+
+func (serverStream) Context() context.Context { return context.Background() }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	logger := &recordingLogger{}
+	interceptor := errors_grpc.StreamServerInterceptor(errors_grpc.WithLogger(logger.log))
+	info := &google_grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	err := interceptor(nil, serverStream{}, info, func(interface{}, google_grpc.ServerStream) error {
+		return errors.New("bad request").WithKind(errors.KindInvalidArgument)
+	})
+	if codes.InvalidArgument != status.Code(err) {
+		t.Errorf("code = %s, want InvalidArgument", status.Code(err))
+	}
+
+	err = interceptor(nil, serverStream{}, info, func(interface{}, google_grpc.ServerStream) error {
+		panic("stream handler")
+	})
+	if codes.Internal != status.Code(err) {
+		t.Errorf("code = %s, want Internal", status.Code(err))
+	}
+	if 2 != len(logger.traces) {
+		t.Errorf("logged %d times, want 2", len(logger.traces))
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	sent := errors_grpc.ErrorView(errors.Wrap(errors.New("no row"), "lookup").WithKind(errors.KindNotFound), errors.InternalView)
+	interceptor := errors_grpc.UnaryClientInterceptor()
+	err := interceptor(context.Background(), "/test.Service/Unary", nil, nil, nil,
+		func(context.Context, string, interface{}, interface{}, *google_grpc.ClientConn, ...google_grpc.CallOption) error {
+			return sent
+		})
+	if !errors.Is(err, errors.KindNotFound) {
+		t.Errorf("the kind was not restored: %v", err)
+	}
+	if "lookup: no row" != err.Error() {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if !errors.IsRemote(err) {
+		t.Error("the error is not marked as remote")
+	}
+}
+
+type clientStream struct {
+	google_grpc.ClientStream
+	err error
+}
+
+func (s *clientStream) RecvMsg(interface{}) error { return s.err }
+
+func TestStreamClientInterceptor(t *testing.T) {
+	interceptor := errors_grpc.StreamClientInterceptor()
+	streamer := func(err error) google_grpc.Streamer {
+		return func(context.Context, *google_grpc.StreamDesc, *google_grpc.ClientConn, string, ...google_grpc.CallOption) (google_grpc.ClientStream, error) {
+			return &clientStream{err: err}, nil
+		}
+	}
+
+	stream, err := interceptor(context.Background(), nil, nil, "/test.Service/Stream",
+		streamer(status.Error(codes.Unavailable, "draining")))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := stream.RecvMsg(nil); !errors.Is(err, errors.KindUnavailable) {
+		t.Errorf("RecvMsg error was not converted: %#v", err)
+	}
+
+	stream, _ = interceptor(context.Background(), nil, nil, "/test.Service/Stream", streamer(io.EOF))
+	if err := stream.RecvMsg(nil); io.EOF != err {
+		t.Errorf("io.EOF was not passed through unchanged: %#v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, nil, "/test.Service/Stream",
+		func(context.Context, *google_grpc.StreamDesc, *google_grpc.ClientConn, string, ...google_grpc.CallOption) (google_grpc.ClientStream, error) {
+			return nil, status.Error(codes.PermissionDenied, "no")
+		})
+	if !errors.Is(err, errors.KindPermissionDenied) {
+		t.Errorf("the error establishing the stream was not converted: %v", err)
+	}
+}
+
+func TestServerInterceptorWithView(t *testing.T) {
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.Wrap(errors.New("no row"), "account lookup failed").WithKind(errors.KindNotFound)
+	}
+
+	interceptor := errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(nil))
+	_, err := interceptor(context.Background(), nil, unaryInfo, handler)
+	if st := status.Convert(err); codes.NotFound != st.Code() || errors.DefaultPublicMessage != st.Message() || 0 != len(st.Details()) {
+		t.Errorf("status = %s %q with %d details, want the public view by default", st.Code(), st.Message(), len(st.Details()))
+	}
+
+	interceptor = errors_grpc.UnaryServerInterceptor(errors_grpc.WithLogger(nil), errors_grpc.WithView(errors.InternalView))
+	_, err = interceptor(context.Background(), nil, unaryInfo, handler)
+	if st := status.Convert(err); codes.NotFound != st.Code() || "account lookup failed" != st.Message() || 0 == len(st.Details()) {
+		t.Errorf("status = %s %q with %d details, want the internal view", st.Code(), st.Message(), len(st.Details()))
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Package grpc converts between error chains built with github.com/bdlm/errors and gRPC statuses.
+
+On the server, ToStatus derives a status code from the chain and, by default, shows the client no
+more than the chain's public message, see errors.PublicMessage. A server whose clients are peer
+services opts in to the internal view with ToStatusView or ErrorView, which packs every frame -- its
+message, caller and fields -- into the status details. On the client, FromStatus unpacks them again
+into an *errors.E chain marked as remote, so the same tools work on either side of the call:
+
+	// server
+	return nil, grpc.Error(errors.Wrap(err, "loading the account").WithKind(errors.KindNotFound))
+
+	// server, for a peer service
+	return nil, grpc.ErrorView(errors.Wrap(err, "loading the account"), errors.InternalView)
+
+	// client
+	if err := grpc.FromError(err); errors.Is(err, errors.KindNotFound) {
+		...
+	}
+*/
+package grpc
+
+import (
+	"encoding/json"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// framesKey is the member of the status detail that holds the frames. A status can carry any
+// number of details, of any type, so the detail this package wrote is recognised by it.
+const framesKey = "github.com/bdlm/errors/v2/grpc.frames"
+
+// Error converts err to a status error in the public view, see ToStatus. It returns nil for a nil
+// error.
+func Error(err error) error {
+	return ErrorView(err, errors.PublicView)
+}
+
+// ErrorView converts err to a status error showing the client the given view of it, see
+// ToStatusView. It returns nil for a nil error.
+func ErrorView(err error, view errors.View) error {
+	if nil == err {
+		return nil
+	}
+	return ToStatusView(err, view).Err()
+}
+
+// FromError converts a status error back to an *errors.E chain, see FromStatus. Any other error,
+// including nil, is returned unchanged.
+func FromError(err error) error {
+	if nil == err {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return FromStatus(st)
+	}
+	return err
+}
+
+// ToStatus converts err to a status in the public view, which is safe to send any client, as the
+// server interceptors do by default. See ToStatusView for the internal view a peer service can be
+// shown instead.
+func ToStatus(err error) *status.Status {
+	return ToStatusView(err, errors.PublicView)
+}
+
+// ToStatusView converts err to a status, showing the client the given view of it.
+//
+// The code is derived from the chain in either view, see Code.
+//
+// In the internal view the message is the outermost message err carries of its own -- never the
+// messages of the errors it wraps -- and the frames themselves, with their callers and fields,
+// travel as a status detail so that FromStatus can rebuild the chain. An error that is itself a
+// status error -- not one wrapping it -- is returned as it is, details and all. Messages and fields
+// are redacted by the policy set with errors.SetRedactionPolicy. This is the view
+// for a peer service, which debugs the call with the same tools as the server.
+//
+// In the public view the message is errors.PublicMessage, and the status carries no details: it is
+// the view for a client that must not learn how the server is built.
+//
+// A nil error is an OK status in either view.
+func ToStatusView(err error, view errors.View) *status.Status {
+	if nil == err {
+		return status.New(codes.OK, "")
+	}
+	if errors.PublicView == view {
+		return status.New(Code(err), errors.PublicMessage(err))
+	}
+	if st, ok := err.(interface{ GRPCStatus() *status.Status }); ok && nil != st.GRPCStatus() {
+		return st.GRPCStatus()
+	}
+	frames := errors.Frames(err)
+	st := status.New(Code(err), errors.RedactMessage(message(err, frames)))
+	if withDetails, detailsErr := st.WithDetails(framesToStruct(frames)); nil == detailsErr {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus converts a status back to an *errors.E chain. Every link of the result is marked as
+// remote, see errors.FromFrames. It returns nil for a nil or OK status.
+//
+// If the status carries frames written by ToStatus they are rebuilt in full; otherwise -- the
+// status came from a server not using this package -- the result is a single frame holding the
+// status message. Either way the outermost kind is the one matching the status code unless the
+// frames already carry one, so Is(err, errors.KindNotFound) works on the client as it did on the
+// server.
+func FromStatus(st *status.Status) *errors.E {
+	if nil == st || codes.OK == st.Code() {
+		return nil
+	}
+	var frames []errors.Frame
+	for _, detail := range st.Details() {
+		if s, ok := detail.(*structpb.Struct); ok {
+			if frames, ok = structToFrames(s); ok {
+				break
+			}
+		}
+	}
+	if 0 == len(frames) {
+		frames = []errors.Frame{{Message: st.Message()}}
+	}
+	err := errors.FromFrames(frames)
+	if nil == errors.KindOf(err) {
+		err = err.WithKind(Kind(st.Code()))
+	}
+	return err
+}
+
+// message is the outermost message err carries of its own. A status error in the chain contributes
+// its status message rather than its Error text, which would nest "rpc error: code = ..." inside
+// the status being built.
+func message(err error, frames []errors.Frame) string {
+	for _, frame := range frames {
+		if st, ok := err.(interface{ GRPCStatus() *status.Status }); ok && nil != st.GRPCStatus() {
+			return st.GRPCStatus().Message()
+		}
+		if "" != frame.Message {
+			return frame.Message
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
+
+func framesToStruct(frames []errors.Frame) *structpb.Struct {
+	list := make([]*structpb.Value, 0, len(frames))
+	for _, frame := range frames {
+		list = append(list, toValue(map[string]interface{}{
+			"message": errors.RedactMessage(frame.Message),
+			"file":    frame.File,
+			"line":    frame.Line,
+			"func":    frame.Func,
+			"code":    string(frame.Code),
+			"kind":    frame.Kind,
+			"fields":  errors.RedactFields(frame.Fields),
+			"public":  errors.RedactMessage(frame.Public),
+			"id":      frame.ID,
+			"remote":  frame.Remote,
+		}))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		framesKey: {Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: list}}},
+	}}
+}
+
+// structToFrames reports false if s is not a detail written by framesToStruct.
+func structToFrames(s *structpb.Struct) ([]errors.Frame, bool) {
+	list := s.GetFields()[framesKey].GetListValue()
+	if nil == list {
+		return nil, false
+	}
+	frames := make([]errors.Frame, 0, len(list.GetValues()))
+	for _, value := range list.GetValues() {
+		members := value.GetStructValue().GetFields()
+		frame := errors.Frame{
+			Message: members["message"].GetStringValue(),
+			File:    members["file"].GetStringValue(),
+			Line:    int(members["line"].GetNumberValue()),
+			Func:    members["func"].GetStringValue(),
+			Code:    errors.Code(members["code"].GetStringValue()),
+			Kind:    members["kind"].GetStringValue(),
+			Public:  members["public"].GetStringValue(),
+			ID:      members["id"].GetStringValue(),
+			Remote:  members["remote"].GetBoolValue(),
+		}
+		if fields, ok := fromValue(members["fields"]).(map[string]interface{}); ok && 0 < len(fields) {
+			frame.Fields = fields
+		}
+		frames = append(frames, frame)
+	}
+	return frames, true
+}
+
+// toValue converts v to a protobuf value by way of its JSON encoding, which is the shape a Struct
+// models. A value that cannot be encoded is carried as its encoding error's message, so a field of
+// an unexpected type costs that field rather than the whole status.
+func toValue(v interface{}) *structpb.Value {
+	byts, err := json.Marshal(v)
+	if nil != err {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: err.Error()}}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(byts, &decoded); nil != err {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: err.Error()}}
+	}
+	return jsonToValue(decoded)
+}
+
+func jsonToValue(v interface{}) *structpb.Value {
+	switch v := v.(type) {
+	case bool:
+		return &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: v}}
+	case float64:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: v}}
+	case string:
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: v}}
+	case []interface{}:
+		list := &structpb.ListValue{Values: make([]*structpb.Value, 0, len(v))}
+		for _, item := range v {
+			list.Values = append(list.Values, jsonToValue(item))
+		}
+		return &structpb.Value{Kind: &structpb.Value_ListValue{ListValue: list}}
+	case map[string]interface{}:
+		s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(v))}
+		for key, item := range v {
+			s.Fields[key] = jsonToValue(item)
+		}
+		return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: s}}
+	}
+	return &structpb.Value{Kind: &structpb.Value_NullValue{}}
+}
+
+// fromValue is the inverse of jsonToValue.
+func fromValue(v *structpb.Value) interface{} {
+	switch kind := v.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		return kind.BoolValue
+	case *structpb.Value_NumberValue:
+		return kind.NumberValue
+	case *structpb.Value_StringValue:
+		return kind.StringValue
+	case *structpb.Value_ListValue:
+		ret := make([]interface{}, 0, len(kind.ListValue.GetValues()))
+		for _, item := range kind.ListValue.GetValues() {
+			ret = append(ret, fromValue(item))
+		}
+		return ret
+	case *structpb.Value_StructValue:
+		ret := make(map[string]interface{}, len(kind.StructValue.GetFields()))
+		for key, item := range kind.StructValue.GetFields() {
+			ret[key] = fromValue(item)
+		}
+		return ret
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package grpc_test
+
+import (
+	"context"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bdlm/errors/v2"
+	errors_grpc "github.com/bdlm/errors/v2/grpc"
+)
+
+var errTokenExpired = errors.NewKind("grpc_test.token_expired", errors.KindUnauthenticated)
+
+func TestCode(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want codes.Code
+	}{
+		"nil":                   {nil, codes.OK},
+		"plain":                 {errors.New("x"), codes.Unknown},
+		"canonical kind":        {errors.Wrap(errors.New("x").WithKind(errors.KindNotFound), "a"), codes.NotFound},
+		"descendant kind":       {errors.New("x").WithKind(errTokenExpired), codes.Unauthenticated},
+		"wrapped status":        {errors.Wrap(status.Error(codes.PermissionDenied, "no"), "a"), codes.PermissionDenied},
+		"status as annotation":  {errors.WrapE(errors.New("cause"), status.Error(codes.Internal, "internal")), codes.Internal},
+		"kind beats status":     {errors.Wrap(status.Error(codes.Internal, "x"), "a").WithKind(errors.KindInvalidArgument), codes.InvalidArgument},
+		"context canceled":      {errors.Wrap(context.Canceled, "a"), codes.Canceled},
+		"context deadline":      {fmt.Errorf("f: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		"custom root kind":      {errors.New("x").WithKind(errors.NewKind("grpc_test.root", nil)), codes.Unknown},
+		"kind in a join branch": {std_errors.Join(errors.New("x"), errors.New("y").WithKind(errors.KindUnavailable)), codes.Unavailable},
+	} {
+		if got := errors_grpc.Code(tc.err); tc.want != got {
+			t.Errorf("%s: Code = %s, want %s", name, got, tc.want)
+		}
+	}
+}
+
+func TestToStatusMessageIsTheOutermostOwnMessage(t *testing.T) {
+	err := errors.Wrap(errors.Wrap(errors.New("dial tcp 10.0.0.3:5432: refused"), "querying accounts"), "account lookup failed")
+	st := errors_grpc.ToStatusView(err, errors.InternalView)
+	if got, want := st.Message(), "account lookup failed"; want != got {
+		t.Errorf("Message = %q, want %q: the causes must not leak into it", got, want)
+	}
+
+	traced := errors.Trace(errors.Wrap(errors.New("inner"), "outer"))
+	if got := errors_grpc.ToStatusView(traced, errors.InternalView).Message(); "outer" != got {
+		t.Errorf("a frame with no message of its own should be skipped, got %q", got)
+	}
+
+	wrappedStatus := errors.Wrap(status.Error(codes.NotFound, "no such account"), "")
+	if got := errors_grpc.ToStatusView(wrappedStatus, errors.InternalView).Message(); "no such account" != got {
+		t.Errorf("a wrapped status should contribute its own message, got %q", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	inner := errors.New("row missing").WithCode("account_missing").WithField("account", "a-1")
+	err := errors.Wrap(inner, "loading the account").WithKind(errTokenExpired).WithField("tenant", "t-9")
+
+	received := errors_grpc.FromError(errors_grpc.ErrorView(err, errors.InternalView))
+	got, ok := received.(*errors.E)
+	if !ok {
+		t.Fatalf("FromError returned %T, want *errors.E", received)
+	}
+
+	if err.Error() != got.Error() {
+		t.Errorf("Error() = %q, want %q", got.Error(), err.Error())
+	}
+	if fmt.Sprintf("%+v", err) != fmt.Sprintf("%+v", got) {
+		t.Errorf("%%+v differs:\n got: %+v\nwant: %+v", got, err)
+	}
+	if !errors.IsRemote(got) || !errors.IsRemote(got.Unwrap()) {
+		t.Error("the rebuilt frames are not marked as remote")
+	}
+	if !errors.Is(got, errors.KindUnauthenticated) || !errors.Is(got, errTokenExpired) {
+		t.Error("the kind did not survive the trip")
+	}
+	if "account_missing" != errors.CodeOf(got) {
+		t.Errorf("CodeOf = %q", errors.CodeOf(got))
+	}
+	fields := errors.Fields(got)
+	if "a-1" != fields["account"] || "t-9" != fields["tenant"] {
+		t.Errorf("Fields = %v", fields)
+	}
+	if errors.Caller(err).Line() != errors.Caller(got).Line() || errors.Caller(err).Func() != errors.Caller(got).Func() {
+		t.Errorf("caller = %v, want %v", errors.Caller(got), errors.Caller(err))
+	}
+}
+
+func TestFromStatusWithoutFrames(t *testing.T) {
+	err := errors_grpc.FromStatus(status.New(codes.NotFound, "no such account"))
+	if "no such account" != err.Error() {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if !errors.Is(err, errors.KindNotFound) {
+		t.Error("the status code was not mapped to a kind")
+	}
+	if !errors.IsRemote(err) {
+		t.Error("the frame is not marked as remote")
+	}
+}
+
+func TestNilAndOK(t *testing.T) {
+	if codes.OK != errors_grpc.ToStatus(nil).Code() {
+		t.Error("a nil error is not an OK status")
+	}
+	if nil != errors_grpc.Error(nil) || nil != errors_grpc.ErrorView(nil, errors.InternalView) || nil != errors_grpc.FromError(nil) {
+		t.Error("nil did not convert to nil")
+	}
+	if nil != errors_grpc.FromStatus(nil) || nil != errors_grpc.FromStatus(status.New(codes.OK, "")) {
+		t.Error("a nil or OK status did not convert to nil")
+	}
+	plain := std_errors.New("not a status")
+	if plain != errors_grpc.FromError(plain) {
+		t.Error("FromError changed an error that is not a status")
+	}
+}
+
+func TestToStatusPassesAStatusErrorThrough(t *testing.T) {
+	st, _ := status.New(codes.FailedPrecondition, "not yet").WithDetails(&structpb.Struct{})
+	if got := errors_grpc.ToStatusView(st.Err(), errors.InternalView); codes.FailedPrecondition != got.Code() || 1 != len(got.Details()) {
+		t.Errorf("status = %s with %d details, want the original", got.Code(), len(got.Details()))
+	}
+}
+
+func TestUnavailableIsRetryable(t *testing.T) {
+	if !errors.IsRetryable(errors.Wrap(status.Error(codes.Unavailable, "down"), "calling upstream")) {
+		t.Error("a status of codes.Unavailable is not retryable")
+	}
+	if !errors.IsRetryable(errors_grpc.FromError(status.Error(codes.Unavailable, "down"))) {
+		t.Error("a rebuilt status of codes.Unavailable is not retryable")
+	}
+	if errors.IsRetryable(status.Error(codes.InvalidArgument, "bad")) {
+		t.Error("a status of codes.InvalidArgument is retryable")
+	}
+}
+
+func TestToStatusViewPublic(t *testing.T) {
+	err := errors.Wrap(errors.New("dial tcp 10.0.0.3:5432: refused"), "querying accounts").
+		WithKind(errors.KindUnavailable).WithPublic("accounts are unavailable")
+	st := errors_grpc.ToStatusView(err, errors.PublicView)
+	if codes.Unavailable != st.Code() || "accounts are unavailable" != st.Message() || 0 != len(st.Details()) {
+		t.Errorf("status = %s %q with %d details, want the public message alone", st.Code(), st.Message(), len(st.Details()))
+	}
+
+	downstream, _ := status.New(codes.NotFound, "no row in accounts").WithDetails(&structpb.Struct{})
+	st = errors_grpc.ToStatusView(downstream.Err(), errors.PublicView)
+	if codes.NotFound != st.Code() || errors.DefaultPublicMessage != st.Message() || 0 != len(st.Details()) {
+		t.Errorf("status = %s %q with %d details, want a status error rebuilt without them", st.Code(), st.Message(), len(st.Details()))
+	}
+}
+
+func TestToStatusDefaultsToThePublicView(t *testing.T) {
+	err := errors.Wrap(errors.New("dial tcp 10.0.0.3:5432: refused").WithField("host", "db-1"), "querying accounts").
+		WithKind(errors.KindUnavailable).WithID()
+	st := errors_grpc.ToStatus(err)
+	if codes.Unavailable != st.Code() || errors.DefaultPublicMessage != st.Message() || 0 != len(st.Details()) {
+		t.Errorf("ToStatus = %s %q with %d details, want the public view", st.Code(), st.Message(), len(st.Details()))
+	}
+	received := errors_grpc.FromError(errors_grpc.Error(err))
+	if errors.DefaultPublicMessage != received.Error() || "" != errors.ID(received) || 0 != len(errors.Fields(received)) {
+		t.Errorf("Error sent %+v, want nothing but the public message and code", received)
+	}
+	if !errors.Is(received, errors.KindUnavailable) {
+		t.Error("the code did not survive the public view")
+	}
+}
+
+func TestRoundTripKeepsThePublicMessage(t *testing.T) {
+	err := errors.Wrap(errors.New("row missing").WithPublic("no such account"), "loading the account")
+	if got := errors.PublicMessage(errors_grpc.FromError(errors_grpc.ErrorView(err, errors.InternalView))); "no such account" != got {
+		t.Errorf("PublicMessage = %q", got)
+	}
+}
+
+func TestToStatusIsRedacted(t *testing.T) {
+	err := errors.Wrap(errors.New("no account for jane@example.com"), "charging Bearer abc").WithField("api_key", "k-1")
+	st := errors_grpc.ToStatusView(err, errors.InternalView)
+	received := errors_grpc.FromStatus(st)
+	for _, rendered := range []string{st.Message(), received.Error(), fmt.Sprint(errors.Fields(received))} {
+		for _, leak := range []string{"jane@example.com", "abc", "k-1"} {
+			if strings.Contains(rendered, leak) {
+				t.Errorf("the status leaks %q: %s", leak, rendered)
+			}
+		}
+	}
+}
+
+func TestRoundTripKeepsTheID(t *testing.T) {
+	err := errors.Wrap(errors.New("row missing").WithID(), "loading the account").WithID()
+	received := errors_grpc.FromError(errors_grpc.ErrorView(err, errors.InternalView))
+	if errors.ID(err) != errors.ID(received) || errors.Frames(err)[1].ID != errors.Frames(received)[1].ID {
+		t.Errorf("IDs = %v, want %v", errors.Frames(received), errors.Frames(err))
+	}
+}
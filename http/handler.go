@@ -0,0 +1,180 @@
+package http
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	std_http "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// HandlerFunc is an HTTP handler that returns its error rather than writing it.
+//
+// Returning the error lets one adapter respond to every failure the same way: derive the response
+// from the error, log the full trace once, and recover a panic into an error with the stack that
+// produced it. A handler that has already written its response may still return an error, which is
+// then logged but not written.
+type HandlerFunc func(std_http.ResponseWriter, *std_http.Request) error
+
+// ServeHTTP implements http.Handler with the default options, see Handler.
+func (h HandlerFunc) ServeHTTP(w std_http.ResponseWriter, r *std_http.Request) {
+	Handler(h).ServeHTTP(w, r)
+}
+
+// Logger receives every error a handler returns or panics with, exactly once, with the request
+// that produced it.
+type Logger func(r *std_http.Request, err error)
+
+// Option configures Handler.
+type Option func(*handler)
+
+// WithLogger sets the Logger errors are reported to. The default writes the %+v trace to the
+// standard library's log package, with the request line, redacted by the policy set with
+// errors.SetRedactionPolicy; a nil Logger disables logging.
+func WithLogger(logger Logger) Option {
+	return func(h *handler) {
+		h.logger = logger
+	}
+}
+
+// WithView sets the view of an error written as the response, see NewProblemView. The default is
+// errors.PublicView.
+func WithView(view errors.View) Option {
+	return func(h *handler) {
+		h.view = view
+	}
+}
+
+// Handler adapts h to an http.Handler.
+//
+// When h returns an error, or panics, the error is reported to the Logger with its full trace and
+// then written as the response: a problem details document, see NewProblemView, or its plain-text
+// rendering if the request's Accept header prefers text/plain. If h has already written the
+// response header, nothing more is written -- the error is only logged.
+//
+// A panic is recovered with errors.FromPanic, into an error of kind errors.KindPanic whose trace
+// starts at the panic. http.ErrAbortHandler is the exception: it is the standard library's signal
+// to abort the response, and is re-panicked for the server to handle.
+func Handler(h HandlerFunc, opts ...Option) std_http.Handler {
+	ret := &handler{
+		fn: h,
+		logger: func(r *std_http.Request, err error) {
+			log.Print(errors.RedactMessage(fmt.Sprintf("%s %s: %+v", r.Method, r.URL.RequestURI(), err)))
+		},
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+type handler struct {
+	fn     HandlerFunc
+	logger Logger
+	view   errors.View
+}
+
+func (h *handler) ServeHTTP(w std_http.ResponseWriter, r *std_http.Request) {
+	rw := &responseWriter{ResponseWriter: w}
+	var err error
+	defer func() {
+		if p := recover(); nil != p {
+			if p == std_http.ErrAbortHandler {
+				panic(p)
+			}
+			err = errors.FromPanic(p)
+		}
+		if nil == err {
+			return
+		}
+		if nil != h.logger {
+			h.logger(r, err)
+		}
+		if !rw.wroteHeader {
+			write(rw, r, err, h.view)
+		}
+	}()
+	err = h.fn(rw, r)
+}
+
+// write responds with the given view of err, in the representation the request prefers.
+func write(w std_http.ResponseWriter, r *std_http.Request, err error, view errors.View) {
+	problem := NewProblemView(r, err, view)
+	if !prefersText(r.Header.Get("Accept")) {
+		problem.Write(w)
+		return
+	}
+	body := problem.Title
+	if "" != problem.Detail {
+		body += ": " + problem.Detail
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write([]byte(body + "\n"))
+}
+
+// prefersText reports whether an Accept header ranks text/plain above JSON. With no preference
+// expressed, JSON is the default.
+func prefersText(accept string) bool {
+	var textQ, jsonQ float64
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if nil != err {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); nil != err {
+				continue
+			}
+		}
+		switch mediaType {
+		case "text/plain", "text/*":
+			textQ = higher(textQ, q)
+		case ContentType, "application/json", "application/*":
+			jsonQ = higher(jsonQ, q)
+		}
+	}
+	return textQ > jsonQ
+}
+
+func higher(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// responseWriter records whether the response header has been written, which is what decides
+// whether an error can still be reported to the client.
+type responseWriter struct {
+	std_http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(std_http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() std_http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,174 @@
+package http_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	std_http "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	errors_http "github.com/bdlm/errors/v2/http"
+)
+
+type recordingLogger struct {
+	errs   []error
+	traces []string
+}
+
+func (l *recordingLogger) log(r *std_http.Request, err error) {
+	l.errs = append(l.errs, err)
+	l.traces = append(l.traces, fmt.Sprintf("%+v", err))
+}
+
+func serve(h std_http.Handler, accept string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/things/1", nil)
+	if "" != accept {
+		req.Header.Set("Accept", accept)
+	}
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerWritesTheErrorAndLogsItOnce(t *testing.T) {
+	logger := &recordingLogger{}
+	handlerErr := errors.Wrap(errors.New("no row"), "loading the thing").WithKind(errors.KindNotFound).WithPublic("thing not found")
+	h := errors_http.Handler(func(std_http.ResponseWriter, *std_http.Request) error {
+		return handlerErr
+	}, errors_http.WithLogger(logger.log))
+
+	rec := serve(h, "")
+	if std_http.StatusNotFound != rec.Code || errors_http.ContentType != rec.Header().Get("Content-Type") {
+		t.Errorf("response = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `"detail":"thing not found"`) {
+		t.Errorf("body = %s", rec.Body)
+	}
+	if 1 != len(logger.traces) || fmt.Sprintf("%+v", handlerErr) != logger.traces[0] {
+		t.Errorf("logged %q, want the full trace once", logger.traces)
+	}
+}
+
+func TestHandlerRedactsTheDefaultLog(t *testing.T) {
+	var logged bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	h := errors_http.Handler(func(std_http.ResponseWriter, *std_http.Request) error {
+		return errors.Wrap(errors.New("no account for jane@example.com"), "charging Bearer abc.def")
+	})
+	serve(h, "")
+	if !strings.Contains(logged.String(), "GET /things/1: ") || !strings.Contains(logged.String(), errors.Redacted) {
+		t.Errorf("logged %q, want the redacted trace", logged.String())
+	}
+	for _, leak := range []string{"jane@example.com", "abc.def"} {
+		if strings.Contains(logged.String(), leak) {
+			t.Errorf("the default log leaks %q: %s", leak, logged.String())
+		}
+	}
+}
+
+func TestHandlerNegotiatesContent(t *testing.T) {
+	h := errors_http.Handler(func(std_http.ResponseWriter, *std_http.Request) error {
+		return errors.NewPublic("bad input").WithKind(errors.KindInvalidArgument)
+	}, errors_http.WithLogger(nil))
+
+	for accept, wantText := range map[string]bool{
+		"":                                 false,
+		"*/*":                              false,
+		"application/json":                 false,
+		"application/problem+json":         false,
+		"text/plain":                       true,
+		"text/html, text/plain;q=0.9":      true,
+		"application/json, text/plain":     false,
+		"text/plain;q=0.5, application/*":  false,
+		"application/json;q=0.1, text/*":   true,
+		"text/plain;q=nonsense, text/html": false,
+	} {
+		rec := serve(h, accept)
+		isText := strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain")
+		if wantText != isText {
+			t.Errorf("Accept %q: Content-Type = %q", accept, rec.Header().Get("Content-Type"))
+		}
+		if isText && "Bad Request: bad input\n" != rec.Body.String() {
+			t.Errorf("Accept %q: body = %q", accept, rec.Body)
+		}
+	}
+}
+
+func TestHandlerDoesNotDoubleWrite(t *testing.T) {
+	logger := &recordingLogger{}
+	h := errors_http.Handler(func(w std_http.ResponseWriter, r *std_http.Request) error {
+		w.WriteHeader(std_http.StatusAccepted)
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("failed after writing")
+	}, errors_http.WithLogger(logger.log))
+
+	rec := serve(h, "")
+	if std_http.StatusAccepted != rec.Code || "partial" != rec.Body.String() {
+		t.Errorf("the response was overwritten: %d %q", rec.Code, rec.Body)
+	}
+	if 1 != len(logger.errs) {
+		t.Errorf("logged %d times, want once", len(logger.errs))
+	}
+}
+
+func panickingHandler(std_http.ResponseWriter, *std_http.Request) error {
+	panic("nil map write")
+}
+
+func TestHandlerRecoversPanics(t *testing.T) {
+	logger := &recordingLogger{}
+	rec := serve(errors_http.Handler(panickingHandler, errors_http.WithLogger(logger.log)), "")
+
+	if std_http.StatusInternalServerError != rec.Code {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "panickingHandler") {
+		t.Errorf("the trace leaked into the body: %s", rec.Body)
+	}
+	if 1 != len(logger.errs) {
+		t.Fatalf("logged %d times, want once", len(logger.errs))
+	}
+	if !errors.Is(logger.errs[0], errors.KindInternal) {
+		t.Error("a recovered panic is not of kind Internal")
+	}
+	if clr := errors.Caller(logger.errs[0]); !strings.HasSuffix(clr.Func(), ".panickingHandler") {
+		t.Errorf("the trace does not start at the panic: %+v", logger.errs[0])
+	}
+}
+
+func TestHandlerRepanicsErrAbortHandler(t *testing.T) {
+	h := errors_http.Handler(func(std_http.ResponseWriter, *std_http.Request) error {
+		panic(std_http.ErrAbortHandler)
+	}, errors_http.WithLogger(nil))
+	defer func() {
+		if r := recover(); std_http.ErrAbortHandler != r {
+			t.Errorf("recovered %v, want http.ErrAbortHandler re-panicked", r)
+		}
+	}()
+	serve(h, "")
+}
+
+func TestHandlerFuncIsAHandler(t *testing.T) {
+	var h std_http.Handler = errors_http.HandlerFunc(func(w std_http.ResponseWriter, r *std_http.Request) error {
+		_, _ = w.Write([]byte("ok"))
+		return nil
+	})
+	if rec := serve(h, ""); std_http.StatusOK != rec.Code || "ok" != rec.Body.String() {
+		t.Errorf("response = %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestHandlerWithView(t *testing.T) {
+	h := errors_http.Handler(func(std_http.ResponseWriter, *std_http.Request) error {
+		return errors.Wrap(errors.New("no row"), "loading the thing").WithPublic("thing not found")
+	}, errors_http.WithLogger(nil), errors_http.WithView(errors.InternalView))
+	if rec := serve(h, "text/plain"); "Internal Server Error: loading the thing: no row\n" != rec.Body.String() {
+		t.Errorf("body = %q, want the internal view", rec.Body)
+	}
+}
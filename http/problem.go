@@ -0,0 +1,192 @@
+/*
+Package http renders error chains built with github.com/bdlm/errors as HTTP responses.
+
+A response body is a contract with a client, which the package's own JSON output is not: MarshalJSON
+and the %+v family are a debugging format describing internals. This package derives a public
+document from the same error instead -- an RFC 9457 problem details object -- taking its status from
+the chain's code or kind and its detail from the public message, see errors.WithPublic, and never
+the trace.
+
+	func handler(w http.ResponseWriter, r *http.Request) {
+		if err := doWork(r); nil != err {
+			errors_http.WriteProblem(w, r, err)
+			return
+		}
+		...
+	}
+*/
+package http
+
+import (
+	"encoding/json"
+	std_http "net/http"
+	"sync"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// ContentType is the media type of a problem details document.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document.
+type Problem struct {
+	// Type is a URI identifying the problem type. It defaults to "about:blank", meaning the problem
+	// has no semantics beyond its status code.
+	Type string
+	// Title is a short summary of the problem type. For "about:blank" it is the status text.
+	Title string
+	// Status is the HTTP status code.
+	Status int
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI identifying this occurrence of the problem.
+	Instance string
+	// Extensions are additional members, rendered alongside the standard ones. A member with the
+	// name of a standard member is ignored.
+	Extensions map[string]interface{}
+}
+
+// kindStatus maps each canonical kind to its HTTP status, following the mapping gRPC gateways use.
+var kindStatus = map[*errors.Kind]int{
+	errors.KindCanceled:           499,
+	errors.KindUnknown:            std_http.StatusInternalServerError,
+	errors.KindInvalidArgument:    std_http.StatusBadRequest,
+	errors.KindDeadlineExceeded:   std_http.StatusGatewayTimeout,
+	errors.KindNotFound:           std_http.StatusNotFound,
+	errors.KindAlreadyExists:      std_http.StatusConflict,
+	errors.KindPermissionDenied:   std_http.StatusForbidden,
+	errors.KindResourceExhausted:  std_http.StatusTooManyRequests,
+	errors.KindFailedPrecondition: std_http.StatusBadRequest,
+	errors.KindAborted:            std_http.StatusConflict,
+	errors.KindOutOfRange:         std_http.StatusBadRequest,
+	errors.KindUnimplemented:      std_http.StatusNotImplemented,
+	errors.KindInternal:           std_http.StatusInternalServerError,
+	errors.KindUnavailable:        std_http.StatusServiceUnavailable,
+	errors.KindDataLoss:           std_http.StatusInternalServerError,
+	errors.KindUnauthenticated:    std_http.StatusUnauthorized,
+}
+
+// codeStatus holds the statuses registered with RegisterCode.
+var codeStatus sync.Map
+
+// RegisterCode sets the HTTP status used for errors carrying code, see Status.
+func RegisterCode(code errors.Code, status int) {
+	codeStatus.Store(code, status)
+}
+
+// Status returns the HTTP status for err.
+//
+// In order of precedence, it is derived from:
+//
+//   - the nearest code in the chain, if one has been registered with RegisterCode.
+//   - the nearest kind in the chain. A kind that is not canonical maps to the status of its nearest
+//     canonical ancestor.
+//   - the nearest error in the chain with an HTTPStatus() int method.
+//
+// A nil error is 200 OK, and anything else is 500 Internal Server Error.
+func Status(err error) int {
+	if nil == err {
+		return std_http.StatusOK
+	}
+	if status, ok := codeStatus.Load(errors.CodeOf(err)); ok {
+		return status.(int)
+	}
+	for kind := errors.KindOf(err); nil != kind; kind = kind.Parent() {
+		if status, ok := kindStatus[kind]; ok {
+			return status
+		}
+	}
+	var withStatus interface{ HTTPStatus() int }
+	// A status outside the valid range would make WriteHeader panic, so it is treated as absent.
+	if errors.As(err, &withStatus) && 100 <= withStatus.HTTPStatus() && 999 >= withStatus.HTTPStatus() {
+		return withStatus.HTTPStatus()
+	}
+	return std_http.StatusInternalServerError
+}
+
+// NewProblem returns the problem details document for err in the public view, see NewProblemView.
+func NewProblem(r *std_http.Request, err error) *Problem {
+	return NewProblemView(r, err, errors.PublicView)
+}
+
+// NewProblemView returns the problem details document for err, see Status, showing the client the
+// given view of it. r may be nil; if it is not, the request URI is the problem instance.
+//
+// In the public view Detail is the chain's public message, see errors.LookupPublicMessage, or empty
+// if it has none: Title already names the status, and a generic message would say less. In the
+// internal view Detail is the full message, see errors.Message, for a client that is trusted with
+// it. Neither view includes the trace. The chain's code, if any, is the "code" extension member and
+// its instance ID, see errors.ID, is the "id" member. In the internal view the chain's fields, see
+// errors.Fields, are extension members in their own right too; the public view leaves them out,
+// since any frame may have set them, not only the one that decided what the user is shown. Detail
+// and the fields are redacted by the policy set with errors.SetRedactionPolicy.
+func NewProblemView(r *std_http.Request, err error, view errors.View) *Problem {
+	status := Status(err)
+	problem := &Problem{
+		Type:       "about:blank",
+		Title:      std_http.StatusText(status),
+		Status:     status,
+		Extensions: map[string]interface{}{},
+	}
+	if errors.InternalView == view {
+		problem.Detail = errors.Message(err, view)
+		problem.Extensions = errors.RedactFields(errors.Fields(err))
+	} else {
+		problem.Detail, _ = errors.LookupPublicMessage(err)
+	}
+	if code := errors.CodeOf(err); "" != code {
+		problem.Extensions["code"] = code
+	}
+	if id := errors.ID(err); "" != id {
+		problem.Extensions["id"] = id
+	}
+	if nil != r && nil != r.URL {
+		problem.Instance = r.URL.RequestURI()
+	}
+	return problem
+}
+
+// WriteProblem writes the problem details document for err, see NewProblem.
+func WriteProblem(w std_http.ResponseWriter, r *std_http.Request, err error) {
+	NewProblem(r, err).Write(w)
+}
+
+// Write writes the document as a complete response: the content type, the status and the body.
+func (p *Problem) Write(w std_http.ResponseWriter) {
+	body, err := json.Marshal(p)
+	if nil != err {
+		// An extension member that cannot be encoded costs the extensions, not the response.
+		withoutExtensions := *p
+		withoutExtensions.Extensions = nil
+		body, _ = json.Marshal(&withoutExtensions)
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(body)
+}
+
+// MarshalJSON implements the json.Marshaller interface. Extension members are rendered alongside
+// the standard members, as RFC 9457 specifies, and empty standard members are omitted.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	data := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		data[k] = v
+	}
+	for k, v := range map[string]string{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	} {
+		delete(data, k)
+		if "" != v {
+			data[k] = v
+		}
+	}
+	delete(data, "status")
+	if 0 != p.Status {
+		data["status"] = p.Status
+	}
+	return json.Marshal(data)
+}
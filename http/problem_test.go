@@ -0,0 +1,166 @@
+package http_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	std_http "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	errors_http "github.com/bdlm/errors/v2/http"
+)
+
+type teapot struct{}
+
+func (teapot) Error() string   { return "short and stout" }
+func (teapot) HTTPStatus() int { return std_http.StatusTeapot }
+
+type badStatus struct{}
+
+func (badStatus) Error() string   { return "bad status" }
+func (badStatus) HTTPStatus() int { return 0 }
+
+func TestStatus(t *testing.T) {
+	errors_http.RegisterCode("http_test.quota", std_http.StatusPaymentRequired)
+	for name, tc := range map[string]struct {
+		err  error
+		want int
+	}{
+		"nil":                {nil, std_http.StatusOK},
+		"plain":              {errors.New("x"), std_http.StatusInternalServerError},
+		"kind":               {errors.Wrap(errors.New("x").WithKind(errors.KindNotFound), "a"), std_http.StatusNotFound},
+		"descendant kind":    {errors.New("x").WithKind(errors.NewKind("http_test.expired", errors.KindUnauthenticated)), std_http.StatusUnauthorized},
+		"HTTPStatus method":  {errors.Wrap(teapot{}, "a"), std_http.StatusTeapot},
+		"kind beats method":  {errors.Wrap(teapot{}, "a").WithKind(errors.KindUnavailable), std_http.StatusServiceUnavailable},
+		"registered code":    {errors.New("x").WithCode("http_test.quota").WithKind(errors.KindInternal), std_http.StatusPaymentRequired},
+		"unregistered code":  {errors.New("x").WithCode("http_test.unregistered"), std_http.StatusInternalServerError},
+		"out of range":       {errors.Wrap(badStatus{}, "a"), std_http.StatusInternalServerError},
+		"kind in a branch":   {std_errors.Join(errors.New("x"), errors.New("y").WithKind(errors.KindInvalidArgument)), std_http.StatusBadRequest},
+		"fmt wrapped method": {fmt.Errorf("f: %w", teapot{}), std_http.StatusTeapot},
+	} {
+		if got := errors_http.Status(tc.err); tc.want != got {
+			t.Errorf("%s: Status = %d, want %d", name, got, tc.want)
+		}
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	err := errors.Wrap(
+		errors.Wrap(errors.New("pq: relation \"accounts\" does not exist"), "querying accounts"),
+		"loading the account",
+	).WithPublic("account not found").WithKind(errors.KindNotFound).WithCode("account_missing").WithField("account", "a-1")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/accounts/a-1?expand=true", nil)
+	errors_http.WriteProblem(rec, req, err)
+
+	if std_http.StatusNotFound != rec.Code {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if errors_http.ContentType != rec.Header().Get("Content-Type") {
+		t.Errorf("Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); nil != err {
+		t.Fatalf("body is not JSON: %v: %s", err, rec.Body)
+	}
+	for member, want := range map[string]interface{}{
+		"type":     "about:blank",
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "account not found",
+		"instance": "/accounts/a-1?expand=true",
+		"code":     "account_missing",
+	} {
+		if want != body[member] {
+			t.Errorf("%s = %#v, want %#v", member, body[member], want)
+		}
+	}
+	for _, leak := range []string{"pq:", "querying accounts", "loading the account", "problem_test.go", "caller", `"account"`} {
+		if strings.Contains(rec.Body.String(), leak) {
+			t.Errorf("the body leaks %q: %s", leak, rec.Body)
+		}
+	}
+}
+
+func TestProblemStandardMembersWin(t *testing.T) {
+	problem := errors_http.NewProblemView(nil, errors.New("x").WithFields(map[string]interface{}{
+		"status": "spoofed",
+		"detail": "spoofed",
+		"extra":  true,
+	}), errors.InternalView)
+	raw, _ := json.Marshal(problem)
+	var body map[string]interface{}
+	_ = json.Unmarshal(raw, &body)
+	if float64(500) != body["status"] || "x" != body["detail"] || true != body["extra"] {
+		t.Errorf("body = %s", raw)
+	}
+	if _, ok := body["instance"]; ok {
+		t.Errorf("an empty instance was rendered: %s", raw)
+	}
+}
+
+func TestProblemWithAnUnencodableField(t *testing.T) {
+	rec := httptest.NewRecorder()
+	errors_http.NewProblemView(nil, errors.New("x").WithField("ch", make(chan int)), errors.InternalView).Write(rec)
+	if std_http.StatusInternalServerError != rec.Code || !strings.Contains(rec.Body.String(), `"detail":"x"`) {
+		t.Errorf("response = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestNewProblemView(t *testing.T) {
+	err := errors.Wrap(errors.New("no row"), "loading the account").WithKind(errors.KindNotFound)
+	if problem := errors_http.NewProblem(nil, err); "" != problem.Detail || "Not Found" != problem.Title {
+		t.Errorf("public problem = %+v, want no detail without a public message", problem)
+	}
+	if problem := errors_http.NewProblemView(nil, err, errors.InternalView); "loading the account: no row" != problem.Detail {
+		t.Errorf("internal detail = %q, want the full message", problem.Detail)
+	}
+	err = err.WithPublic("no such account")
+	if problem := errors_http.NewProblemView(nil, err, errors.PublicView); "no such account" != problem.Detail {
+		t.Errorf("public detail = %q", problem.Detail)
+	}
+}
+
+func TestProblemIsRedacted(t *testing.T) {
+	err := errors.NewPublic("no account for jane@example.com").WithFields(map[string]interface{}{
+		"password": "hunter2",
+		"account":  "a-1",
+	})
+	raw, _ := json.Marshal(errors_http.NewProblemView(nil, err, errors.InternalView))
+	for _, leak := range []string{"jane@example.com", "hunter2"} {
+		if strings.Contains(string(raw), leak) {
+			t.Errorf("the problem leaks %q: %s", leak, raw)
+		}
+	}
+	if !strings.Contains(string(raw), `"account":"a-1"`) {
+		t.Errorf("problem = %s, want the other fields intact", raw)
+	}
+}
+
+func TestProblemHasTheID(t *testing.T) {
+	err := errors.Wrap(errors.New("no row"), "loading the account").WithID()
+	if id := errors_http.NewProblem(nil, err).Extensions["id"]; errors.ID(err) != id {
+		t.Errorf("id = %v, want %q", id, errors.ID(err))
+	}
+}
+
+func TestPublicProblemLeavesOutFields(t *testing.T) {
+	inner := errors.New("no row").WithField("query", "SELECT * FROM accounts")
+	err := errors.Wrap(inner, "loading the account").WithPublic("no such account").WithCode("account_missing")
+
+	raw, _ := json.Marshal(errors_http.NewProblem(nil, err))
+	if strings.Contains(string(raw), "query") || strings.Contains(string(raw), "SELECT") {
+		t.Errorf("the public problem leaks an inner frame's field: %s", raw)
+	}
+	if !strings.Contains(string(raw), `"code":"account_missing"`) {
+		t.Errorf("problem = %s, want the code", raw)
+	}
+	raw, _ = json.Marshal(errors_http.NewProblemView(nil, err, errors.InternalView))
+	if !strings.Contains(string(raw), `"query":"SELECT * FROM accounts"`) {
+		t.Errorf("internal problem = %s, want the fields", raw)
+	}
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// WithID returns a copy of this frame carrying a new instance ID, see ID.
+//
+// An ID identifies one occurrence of a failure, where Fingerprint identifies the failure. Give one
+// to an error where it crosses a boundary -- returned to a client, say -- so that the ID a user
+// reports leads to the log line the error was written to: the trace verbs, JSON, LogAttrs and the
+// http problem details all include it. An ID reveals nothing about the error, so it is safe to show
+// anyone.
+//
+// Calling WithID on an error that is wrapped again later is fine: each frame keeps its own ID, and
+// ID returns the outermost, so the original is still on the record.
+func (e *E) WithID() *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.id = newID()
+	return cp
+}
+
+// ID returns the outermost instance ID in err's chain, see WithID, or the empty string if no link
+// has one. The chain is searched the same way CodeOf searches it.
+//
+// An ID is 26 characters of Crockford's base 32, as a ULID is: a millisecond timestamp followed by
+// random bits. IDs sort in the order they were created, and IDs created in the same process and
+// millisecond sort in the order of the calls to WithID.
+func ID(err error) string {
+	var id string
+	walk(err, func(link error) bool {
+		if e, ok := link.(*E); ok && nil != e && "" != e.id {
+			id = e.id
+			return true
+		}
+		return false
+	})
+	return id
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// idState is the last ID created. A new ID in the same millisecond increments its random part
+// rather than drawing a new one, so that IDs from one process are strictly ordered.
+var idState struct {
+	sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// newID returns a new instance ID.
+func newID() string {
+	idState.Lock()
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	if ms > idState.ms {
+		idState.ms = ms
+		// A failure leaves the previous bits in place, which with a new timestamp are still unique
+		// within the process.
+		_, _ = rand.Read(idState.entropy[:])
+	} else if !increment(idState.entropy[:]) {
+		// The same millisecond, or an earlier one if the clock went backwards, and the random part
+		// overflowed: borrow the next millisecond.
+		idState.ms++
+	}
+	var raw [16]byte
+	binary.BigEndian.PutUint64(raw[:8], idState.ms<<16)
+	copy(raw[6:], idState.entropy[:])
+	idState.Unlock()
+
+	hi, lo := binary.BigEndian.Uint64(raw[:8]), binary.BigEndian.Uint64(raw[8:])
+	id := make([]byte, 26)
+	for i := range id {
+		// 26 characters of 5 bits hold 130, so the first holds only the top 3 of the 128.
+		shift := uint(5 * (25 - i))
+		var bits uint64
+		switch {
+		case 64 <= shift:
+			bits = hi >> (shift - 64)
+		case 59 < shift:
+			bits = lo>>shift | hi<<(64-shift)
+		default:
+			bits = lo >> shift
+		}
+		id[i] = crockford[bits&31]
+	}
+	return string(id)
+}
+
+// increment adds one to the big-endian number b, and reports false if it overflowed.
+func increment(b []byte) bool {
+	for i := len(b) - 1; 0 <= i; i-- {
+		b[i]++
+		if 0 != b[i] {
+			return true
+		}
+	}
+	return false
+}
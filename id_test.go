@@ -0,0 +1,75 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+var idPattern = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+func TestID(t *testing.T) {
+	inner := errors.New("no row").WithID()
+	outer := errors.Wrap(inner, "loading the account").WithID()
+
+	if !idPattern.MatchString(errors.ID(inner)) {
+		t.Fatalf("ID = %q, want 26 characters of Crockford's base 32", errors.ID(inner))
+	}
+	if errors.ID(outer) == errors.ID(inner) {
+		t.Error("the outer frame did not get an ID of its own")
+	}
+	if got := errors.ID(errors.Wrap(outer, "handling the request")); errors.ID(outer) != got {
+		t.Errorf("ID = %q, want the outermost, %q", got, errors.ID(outer))
+	}
+	if got := errors.ID(fmt.Errorf("f: %w", inner)); errors.ID(inner) != got {
+		t.Errorf("ID through a foreign wrapper = %q", got)
+	}
+	if "" != errors.ID(errors.New("x")) || "" != errors.ID(nil) {
+		t.Error("an error without an ID has one")
+	}
+
+	frames := errors.Frames(outer)
+	if errors.ID(outer) != frames[0].ID || errors.ID(inner) != frames[1].ID {
+		t.Errorf("Frames = %+v, want each frame's own ID", frames)
+	}
+	if errors.ID(outer) != errors.ID(errors.FromFrames(frames)) {
+		t.Error("the ID did not survive Frames and FromFrames")
+	}
+}
+
+func TestIDsAreOrdered(t *testing.T) {
+	ids := make([]string, 1000)
+	seen := map[string]bool{}
+	for i := range ids {
+		ids[i] = errors.ID(errors.New("x").WithID())
+		if seen[ids[i]] {
+			t.Fatalf("ID %s was created twice", ids[i])
+		}
+		seen[ids[i]] = true
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("IDs do not sort in the order they were created")
+	}
+}
+
+func TestIDIsRendered(t *testing.T) {
+	err := errors.Wrap(errors.New("no row").WithID(), "loading the account")
+	id := errors.ID(err)
+
+	if trace := fmt.Sprintf("%+v", err); !strings.Contains(trace, "id="+id+";") {
+		t.Errorf("%%+v = %s, want the ID", trace)
+	}
+	byts, _ := json.Marshal(err)
+	if !strings.Contains(string(byts), `"id":"`+id+`"`) {
+		t.Errorf("MarshalJSON = %s, want the ID", byts)
+	}
+	decoded, _ := errors.FromJSON(byts)
+	if id != errors.ID(decoded) {
+		t.Errorf("FromJSON lost the ID: %s", byts)
+	}
+}
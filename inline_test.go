@@ -0,0 +1,81 @@
+package errors_test
+
+/*
+WARNING - changing the line numbers in this file will break these tests.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	std_caller "github.com/bdlm/std/v2/caller"
+)
+
+// These helpers are small enough for the compiler to inline into their callers, which is the point:
+// an inlined call has no physical frame of its own, so anything deriving a function from a program
+// counter -- runtime.FuncForPC, or runtime.Caller's pc -- names the function it was inlined INTO.
+// Traces built that way attributed errors from small helpers to the wrong function. Wrap is used
+// rather than New because New is itself inlined, which would make the helpers too large to inline.
+
+func inlinedNew() *errors.E {
+	return errors.Wrap(nil, "inlined")
+}
+
+func inlinedWrap(err error) *errors.E {
+	return errors.Wrap(err, "inlined wrap")
+}
+
+// requireInlined skips the test when the build did not inline the helper into its caller, e.g.
+// under -gcflags=-l, since there is then nothing to test. An inlined function has no entry point of
+// its own: its program counters report the entry of the function it was inlined into.
+func requireInlined(t *testing.T, trace std_caller.Trace) {
+	t.Helper()
+	if 2 > len(trace) {
+		t.Fatalf("trace has %d frames", len(trace))
+	}
+	helper, caller := runtime.FuncForPC(trace[0].Pc()), runtime.FuncForPC(trace[1].Pc())
+	if nil == helper || nil == caller || helper.Entry() != caller.Entry() {
+		t.Skip("the helper was not inlined in this build")
+	}
+}
+
+func TestInlinedFramesAreAttributedToTheInlinedFunction(t *testing.T) {
+	err := inlinedNew()
+	clr := errors.Caller(err)
+	requireInlined(t, clr.Trace())
+
+	if !strings.HasSuffix(clr.Func(), ".inlinedNew") {
+		t.Errorf("Func = %s, want the inlined helper", clr.Func())
+	}
+	if !strings.HasSuffix(clr.File(), "inline_test.go") || 25 != clr.Line() {
+		t.Errorf("caller = %s:%d, want inline_test.go:25", clr.File(), clr.Line())
+	}
+	if !strings.HasPrefix(clr.(fmt.Stringer).String(), clr.Func()+":") {
+		t.Errorf("String = %s, want it to name %s", clr.(fmt.Stringer).String(), clr.Func())
+	}
+
+	// The function the helper was inlined into is a frame of its own, right after it.
+	if trace := clr.Trace(); !strings.HasSuffix(trace[1].Func(), ".TestInlinedFramesAreAttributedToTheInlinedFunction") {
+		t.Errorf("trace[1] = %s, want the test function", trace[1].Func())
+	}
+}
+
+func TestInlinedFramesInRenderedOutput(t *testing.T) {
+	err := inlinedWrap(inlinedNew())
+	requireInlined(t, errors.Caller(err).Trace())
+
+	trace := fmt.Sprintf("%+v", err)
+	if !strings.Contains(trace, "inline_test.go:29 (github.com/bdlm/errors/v2_test.inlinedWrap)") ||
+		!strings.Contains(trace, "inline_test.go:25 (github.com/bdlm/errors/v2_test.inlinedNew)") {
+		t.Errorf("%%+v misattributes inlined frames: %s", trace)
+	}
+
+	raw, _ := json.Marshal(err)
+	if !strings.Contains(string(raw), "(github.com/bdlm/errors/v2_test.inlinedWrap)") {
+		t.Errorf("MarshalJSON misattributes inlined frames: %s", raw)
+	}
+}
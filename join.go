@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Join returns an error that wraps every non-nil error in errs, recording where the join happened.
+// It returns nil if errs holds no non-nil error.
+//
+// It is the standard library's errors.Join with caller data. An *E can only implement the
+// single-error Unwrap() error, so the result is an *E whose one wrapped error is the join itself,
+// an unexported type implementing Unwrap() []error. Is and As, here and in the standard library,
+// search every branch through it.
+//
+// A join made by this package in errs is flattened into the result, so appending to a join in a
+// loop yields one join rather than a nest of them; a join that has been decorated in any way -- with
+// a code, kind, fields, a public message or an ID -- is kept as a branch of its own, so that nothing
+// attached to it is lost.
+// Any other error implementing Unwrap() []error -- errors.Join, a multi-%w fmt.Errorf -- is kept as
+// a branch too, since it may carry a message of its own.
+//
+// Error() is the branches' messages separated by "; ", on one line like every other message of this
+// package rather than on one line each as errors.Join has it. The trace formats render each branch
+// as a frame.
+func Join(errs ...error) *E {
+	return join(errs)
+}
+
+// Append returns err joined with errs, as Join does: if err is itself a join, errs are added to its
+// branches, and if err is nil the result is the join of errs alone. The result records where
+// Append was called.
+//
+//	var result *errors.E
+//	for _, item := range items {
+//		result = errors.Append(result, validate(item))
+//	}
+//	if nil != result {
+//		return result
+//	}
+//
+// A nil *E, in err or errs, counts as nil even though stored in an error it is not a nil error.
+func Append(err error, errs ...error) *E {
+	return join(append([]error{err}, errs...))
+}
+
+// join is Join, with the caller data recorded at the exported function's call site.
+func join(errs []error) *E {
+	branches := []error{}
+	for _, err := range errs {
+		if nil == err {
+			continue
+		}
+		if e, ok := err.(*E); ok {
+			if nil == e {
+				continue
+			}
+			if j, ok := joinOf(e); ok {
+				branches = append(branches, j.errs...)
+				continue
+			}
+		}
+		branches = append(branches, err)
+	}
+	if 0 == len(branches) {
+		return nil
+	}
+	return &E{
+		caller: newCaller(4),
+		prev:   &joined{errs: branches},
+	}
+}
+
+// joinOf returns the join e wraps, if e is an undecorated join made by this package: a frame with
+// nothing but the join and where it was made, so that flattening it loses nothing. Comparing the
+// rest of the frame with the zero value, rather than checking its members one by one, keeps the
+// test right when E gains another.
+func joinOf(e *E) (*joined, bool) {
+	j, ok := e.prev.(*joined)
+	if !ok {
+		return nil, false
+	}
+	rest := *e
+	rest.caller, rest.prev = nil, nil
+	if !reflect.DeepEqual(E{}, rest) {
+		return nil, false
+	}
+	return j, true
+}
+
+// joined is the error Join wraps: several errors, none of which is the cause of another.
+type joined struct {
+	errs []error
+}
+
+// Error implements error.
+func (j *joined) Error() string {
+	msgs := make([]string, 0, len(j.errs))
+	for _, err := range j.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns every joined error, for Is and As.
+func (j *joined) Unwrap() []error {
+	return j.errs
+}
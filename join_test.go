@@ -0,0 +1,108 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestJoin(t *testing.T) {
+	err := errors.Join(errWanted, nil, errors.Wrap(errOther, "context"))
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestJoin") {
+		t.Errorf("caller is %s, want the test", errors.Caller(err).Func())
+	}
+	if want := errWanted.Error() + "; context: " + errOther.Error(); want != err.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	for _, target := range []error{errWanted, errOther} {
+		if !errors.Is(err, target) || !std_errors.Is(err, target) {
+			t.Errorf("%v is not found in the join", target)
+		}
+	}
+	var multi interface{ Unwrap() []error }
+	if !std_errors.As(err, &multi) || 2 != len(multi.Unwrap()) {
+		t.Error("the join does not unwrap to its branches")
+	}
+
+	if nil != errors.Join() || nil != errors.Join(nil, nil) {
+		t.Error("a join of nothing should be nil")
+	}
+	var nilE *errors.E
+	if nil != errors.Join(nilE) {
+		t.Error("a nil *E should count as nil")
+	}
+}
+
+func TestJoinFlattensNestedJoins(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+	branches := func(err error) int {
+		var multi interface{ Unwrap() []error }
+		if !std_errors.As(err, &multi) {
+			return 0
+		}
+		return len(multi.Unwrap())
+	}
+
+	if n := branches(errors.Join(errors.Join(a, b), c)); 3 != n {
+		t.Errorf("a nested join has %d branches, want 3", n)
+	}
+	if n := branches(errors.Join(errors.Join(a, b).WithCode("c"), c)); 2 != n {
+		t.Errorf("a decorated join has %d branches, want it kept whole", n)
+	}
+	if err := errors.Join(errors.Join(a, b).WithPublic("msg"), c); 2 != branches(err) || "msg" != errors.PublicMessage(err) {
+		t.Errorf("a join with a public message has %d branches and public message %q, want it kept whole", branches(err), errors.PublicMessage(err))
+	}
+	if inner := errors.Join(a, b).WithID(); errors.ID(inner) != errors.ID(errors.Join(inner, c)) {
+		t.Errorf("a join with an ID was flattened and lost it: %d branches", branches(errors.Join(inner, c)))
+	}
+	if n := branches(errors.Join(std_errors.Join(a, b), c)); 2 != n {
+		t.Errorf("a foreign join has %d branches, want it kept whole", n)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	var result *errors.E
+	for _, msg := range []string{"", "a", "", "b"} {
+		var err error
+		if "" != msg {
+			err = errors.New(msg)
+		}
+		result = errors.Append(result, err)
+	}
+	if nil == result || "a; b" != result.Error() {
+		t.Fatalf("Append = %v", result)
+	}
+	if !strings.HasSuffix(errors.Caller(result).Func(), ".TestAppend") {
+		t.Errorf("caller is %s, want the test", errors.Caller(result).Func())
+	}
+	if nil != errors.Append(nil) {
+		t.Error("appending nothing to nil should be nil")
+	}
+}
+
+func TestJoinRendering(t *testing.T) {
+	err := errors.Join(errors.New("a"), errors.New("b"))
+	if "a; b" != fmt.Sprintf("%v", err) {
+		t.Errorf("%%v = %q", fmt.Sprintf("%v", err))
+	}
+	if "a; b" != fmt.Sprintf("%v", errors.Trace(err)) {
+		t.Errorf("%%v of a traced join = %q", fmt.Sprintf("%v", errors.Trace(err)))
+	}
+	if out := fmt.Sprintf("%#v", err); `[{"error":"a; b"}]` != out {
+		t.Errorf("%%#v = %s", out)
+	}
+	trace := fmt.Sprintf("%+v", err)
+	for _, want := range []string{"#0 join_test.go", "a - #0.0.0 join_test.go", "b - #0.1.0 join_test.go"} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("%%+v = %q, want it to contain %q", trace, want)
+		}
+	}
+	byts, _ := json.Marshal(err)
+	if !strings.Contains(string(byts), `"causes":[[{`) || !strings.Contains(string(byts), `"error":"b"`) {
+		t.Errorf("MarshalJSON = %s", byts)
+	}
+}
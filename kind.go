@@ -0,0 +1,139 @@
+package errors
+
+import (
+	"sync"
+)
+
+// Kind is a category of failure, such as "not found" or "permission denied".
+//
+// A Kind answers the question a caller most often needs answered -- what sort of failure is this --
+// independently of which sentinel or which message produced it. Kinds form a hierarchy: a kind may
+// declare a parent, and an error of a kind also matches every ancestor of that kind, so a service
+// can define ErrTokenExpired as a kind of KindUnauthenticated and have code that only knows about
+// KindUnauthenticated handle it correctly.
+//
+// A Kind is itself an error, so it can be matched with Is, used as a sentinel, or wrapped directly:
+//
+//	err := errors.New("no such user").WithKind(errors.KindNotFound)
+//	errors.Is(err, errors.KindNotFound) // true
+//
+// Kinds are compared by identity. Create each one once, with NewKind, and share it.
+type Kind struct {
+	name   string
+	parent *Kind
+}
+
+// The canonical kinds. They mirror the gRPC status codes, which are the most widely shared
+// vocabulary for this, so that integrations can map between the two without losing information.
+var (
+	KindCanceled           = NewKind("canceled", nil)
+	KindUnknown            = NewKind("unknown", nil)
+	KindInvalidArgument    = NewKind("invalid_argument", nil)
+	KindDeadlineExceeded   = NewKind("deadline_exceeded", nil)
+	KindNotFound           = NewKind("not_found", nil)
+	KindAlreadyExists      = NewKind("already_exists", nil)
+	KindPermissionDenied   = NewKind("permission_denied", nil)
+	KindResourceExhausted  = NewKind("resource_exhausted", nil)
+	KindFailedPrecondition = NewKind("failed_precondition", nil)
+	KindAborted            = NewKind("aborted", nil)
+	KindOutOfRange         = NewKind("out_of_range", nil)
+	KindUnimplemented      = NewKind("unimplemented", nil)
+	KindInternal           = NewKind("internal", nil)
+	KindUnavailable        = NewKind("unavailable", nil)
+	KindDataLoss           = NewKind("data_loss", nil)
+	KindUnauthenticated    = NewKind("unauthenticated", nil)
+)
+
+// kinds indexes every kind by name, so that a kind named in an error received from another
+// process -- see FromFrames -- resolves to the same value, and Is can match it.
+var kinds sync.Map
+
+// NewKind returns a new Kind. parent may be nil; if it is not, the new kind matches parent, and
+// everything parent matches, under Is.
+//
+// Names should be unique. A kind received from another process is resolved by name, and if two
+// kinds share one, the first created is the one it resolves to.
+func NewKind(name string, parent *Kind) *Kind {
+	kind := &Kind{
+		name:   name,
+		parent: parent,
+	}
+	kinds.LoadOrStore(name, kind)
+	return kind
+}
+
+// LookupKind returns the kind created with name, or nil if there is none.
+func LookupKind(name string) *Kind {
+	if kind, ok := kinds.Load(name); ok {
+		return kind.(*Kind)
+	}
+	return nil
+}
+
+// Error implements error. A kind's message is its name.
+func (k *Kind) Error() string {
+	return k.Name()
+}
+
+// Is reports whether test is this kind or one of its ancestors. It is the hook the standard
+// library's errors.Is, and this package's Is, consult.
+func (k *Kind) Is(test error) bool {
+	for ; nil != k; k = k.parent {
+		if kind, ok := test.(*Kind); ok && k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the kind's name.
+func (k *Kind) Name() string {
+	if nil == k {
+		return ""
+	}
+	return k.name
+}
+
+// Parent returns the kind this kind descends from, or nil.
+func (k *Kind) Parent() *Kind {
+	if nil == k {
+		return nil
+	}
+	return k.parent
+}
+
+// WithKind returns a copy of this frame carrying kind. The receiver is not modified, see WithField.
+func (e *E) WithKind(kind *Kind) *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.kind = kind
+	return cp
+}
+
+// KindOf returns the kind nearest the top of err's chain, or nil if no link has one. A Kind found in
+// the chain as an error in its own right counts, as well as one attached with WithKind.
+//
+// The chain is searched the same way CodeOf searches it.
+func KindOf(err error) *Kind {
+	var kind *Kind
+	walk(err, func(link error) bool {
+		switch link := link.(type) {
+		case *E:
+			kind = link.kindOf()
+		case *Kind:
+			kind = link
+		}
+		return nil != kind
+	})
+	return kind
+}
+
+// kindOf is this frame's own kind, nil-safe.
+func (e *E) kindOf() *Kind {
+	if nil == e {
+		return nil
+	}
+	return e.kind
+}
@@ -0,0 +1,106 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+var (
+	errTokenExpired = errors.NewKind("token_expired", errors.KindUnauthenticated)
+	errTokenRevoked = errors.NewKind("token_revoked", errTokenExpired)
+)
+
+func TestKindMatchesItselfAndItsAncestors(t *testing.T) {
+	err := errors.Wrap(errors.New("jwt: exp in the past").WithKind(errTokenRevoked), "authenticating")
+
+	for _, kind := range []*errors.Kind{errTokenRevoked, errTokenExpired, errors.KindUnauthenticated} {
+		if !errors.Is(err, kind) {
+			t.Errorf("Is(err, %s) = false, want true", kind.Name())
+		}
+		if !std_errors.Is(err, kind) {
+			t.Errorf("std errors.Is(err, %s) = false, want true", kind.Name())
+		}
+	}
+	for _, kind := range []*errors.Kind{errors.KindPermissionDenied, errors.KindNotFound} {
+		if errors.Is(err, kind) || std_errors.Is(err, kind) {
+			t.Errorf("matched unrelated kind %s", kind.Name())
+		}
+	}
+	// Matching runs up the hierarchy, never down it.
+	if errors.Is(errors.New("x").WithKind(errors.KindUnauthenticated), errTokenExpired) {
+		t.Error("a parent kind matched one of its descendants")
+	}
+}
+
+func TestKindAsAnError(t *testing.T) {
+	wrapped := errors.Wrap(errTokenExpired, "authenticating")
+	if !errors.Is(wrapped, errors.KindUnauthenticated) || !std_errors.Is(wrapped, errors.KindUnauthenticated) {
+		t.Error("a wrapped kind did not match its parent")
+	}
+	if !std_errors.Is(errTokenExpired, errors.KindUnauthenticated) {
+		t.Error("a kind did not match its parent directly")
+	}
+	annotated := errors.WrapE(io.EOF, errTokenExpired)
+	if !errors.Is(annotated, errors.KindUnauthenticated) || !std_errors.Is(annotated, errors.KindUnauthenticated) {
+		t.Error("a kind given to WrapE did not match its parent")
+	}
+	if errTokenExpired != errors.KindOf(annotated) {
+		t.Errorf("KindOf = %v, want the annotation's kind", errors.KindOf(annotated))
+	}
+	if errors.Is(annotated, errors.KindPermissionDenied) {
+		t.Error("a kind given to WrapE matched an unrelated kind")
+	}
+	if "token_expired" != errTokenExpired.Error() {
+		t.Errorf("Error() = %q, want the kind's name", errTokenExpired.Error())
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	inner := errors.New("no row").WithKind(errors.KindNotFound)
+	for name, tc := range map[string]struct {
+		err  error
+		want *errors.Kind
+	}{
+		"nil":               {nil, nil},
+		"no kind":           {errors.Wrap(sentinel, "a"), nil},
+		"attached":          {errors.Wrap(inner, "a"), errors.KindNotFound},
+		"outer wins":        {errors.Wrap(inner, "a").WithKind(errors.KindInternal), errors.KindInternal},
+		"a kind in a chain": {fmt.Errorf("f: %w", errTokenExpired), errTokenExpired},
+		"in a join branch":  {std_errors.Join(other, inner), errors.KindNotFound},
+	} {
+		if got := errors.KindOf(tc.err); tc.want != got {
+			t.Errorf("%s: KindOf = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestKindNilSafety(t *testing.T) {
+	var nilKind *errors.Kind
+	var nilE *errors.E
+	if "" != nilKind.Name() || nil != nilKind.Parent() || nilKind.Is(errors.KindInternal) {
+		t.Error("a nil kind is not inert")
+	}
+	if nil != nilE.WithKind(errors.KindInternal) {
+		t.Error("WithKind on a nil receiver returned a non-nil error")
+	}
+	if errors.Is(errors.New("x").WithKind(nil), errors.KindInternal) {
+		t.Error("a nil kind matched")
+	}
+}
+
+func TestKindIsRenderedPerFrame(t *testing.T) {
+	err := errors.Wrap(errors.New("inner").WithKind(errTokenExpired), "outer")
+	raw, _ := json.Marshal(err)
+	if !strings.Contains(string(raw), `"kind":"token_expired"`) {
+		t.Errorf("MarshalJSON omitted the kind: %s", raw)
+	}
+	if out := fmt.Sprintf("%#+v", err); !strings.Contains(out, `"kind":"token_expired"`) {
+		t.Errorf("%%#+v omitted the kind: %s", out)
+	}
+}
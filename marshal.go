@@ -1,73 +1,306 @@
 package errors
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"path"
-	"runtime"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	std_caller "github.com/bdlm/std/v2/caller"
+)
+
+// JSONSchema is a version of the JSON shape MarshalJSON and the %#v verbs write.
+type JSONSchema int
+
+const (
+	// JSONSchemaV1 is the original shape, and the default: each frame's caller is a single
+	// preformatted string, "#0 file.go:12 (pkg.Func)".
+	JSONSchemaV1 JSONSchema = 1
+
+	// JSONSchemaV2 gives each frame separate members for its location, so a log pipeline can index
+	// them without parsing a string:
+	//
+	//	{
+	//	  "schema": 2,
+	//	  "index": 0,
+	//	  "file": "/src/svc/handler.go",
+	//	  "base": "handler.go",
+	//	  "line": 40,
+	//	  "function": "example.com/svc.(*Server).Handle",
+	//	  "package": "example.com/svc",
+	//	  "error": "request failed"
+	//	}
+	//
+	// A frame without caller data has no location members. With JSONFormat.Trace set, a frame that
+	// has a trace adds a "trace" array of objects with the same location members, innermost call
+	// first. The code, kind, fields and remote members are as in JSONSchemaV1.
+	JSONSchemaV2 JSONSchema = 2
 )
 
-// MarshalJSON implements the json.Marshaller interface.
+// JSONFormat selects the JSON MarshalJSON and the %#v verbs write.
+type JSONFormat struct {
+	// Schema is the shape to write. The zero value is JSONSchemaV1.
+	Schema JSONSchema
+	// Trace adds each frame's full trace, in schemas that support it.
+	Trace bool
+	// OmitFingerprint leaves out the "fingerprint" member the chain forms, MarshalJSON and %#+v,
+	// add to the outermost frame, see Fingerprint.
+	OmitFingerprint bool
+}
+
+var jsonFormatValue atomic.Value
+
+func init() {
+	jsonFormatValue.Store(JSONFormat{Schema: JSONSchemaV1})
+}
+
+// SetJSONFormat sets the JSON MarshalJSON and the %#v verbs write, for every error. The default is
+// JSONSchemaV1, without traces, which is the shape this package has always written plus a
+// fingerprint; choose a later schema once whatever reads the output understands it.
+// MarshalJSONFormat overrides the setting for a single call.
+func SetJSONFormat(format JSONFormat) {
+	jsonFormatValue.Store(format)
+}
+
+// jsonFormat returns the format set with SetJSONFormat.
+func jsonFormat() JSONFormat {
+	return jsonFormatValue.Load().(JSONFormat)
+}
+
+// MarshalJSON implements the json.Marshaller interface. It writes the format set with SetJSONFormat.
 func (e *E) MarshalJSON() ([]byte, error) {
+	return e.MarshalJSONFormat(jsonFormat())
+}
+
+// MarshalJSONFormat is MarshalJSON writing format rather than the one set with SetJSONFormat.
+func (e *E) MarshalJSONFormat(format JSONFormat) ([]byte, error) {
 	if nil == e {
 		return []byte("null"), nil
 	}
-	var lastE, nextE error
-	var key int
+	return json.Marshal(chainJSON(e, format))
+}
+
+// chainJSON is the JSON array describing err's chain, shared by MarshalJSON and the %#+v verb: one
+// object per link, outermost first, as traceLinks has them. The branches of an error with several
+// causes are a "causes" member of the frame they belong to, see treeLinks, each one an array of its
+// own.
+func chainJSON(err error, format JSONFormat) []map[string]interface{} {
+	links, branches := treeLinks(traceLinks(err))
 	jsonData := []map[string]interface{}{}
+	for key, link := range links {
+		jsonData = append(jsonData, frameJSON(key, link, true, format))
+	}
+	if !format.OmitFingerprint {
+		jsonData[0]["fingerprint"] = Fingerprint(err)
+		// The fingerprint covers the branches, which are not errors of their own.
+		format.OmitFingerprint = true
+	}
+	if 0 < len(branches) {
+		causes := [][]map[string]interface{}{}
+		for _, branch := range branches {
+			causes = append(causes, chainJSON(branch, format))
+		}
+		jsonData[len(jsonData)-1]["causes"] = causes
+	}
+	return jsonData
+}
 
-	for key, nextE = range list(e) {
-		data := map[string]interface{}{}
-		err, ok := nextE.(*E)
+// frameJSON is the JSON object describing one link of a chain, shared by MarshalJSON and the JSON
+// format verbs so the two cannot drift apart. withCaller adds the caller members, which the plain
+// %#v verb omits.
+func frameJSON(key int, nextE error, withCaller bool, format JSONFormat) map[string]interface{} {
+	data := map[string]interface{}{}
+	err, ok := nextE.(*E)
+	ok = ok && nil != err
+	switch {
+	case JSONSchemaV2 == format.Schema:
+		data["schema"] = int(JSONSchemaV2)
+		data["index"] = key
+		if withCaller && ok && nil != err.Caller() {
+			locationJSON(data, err.Caller())
+			if format.Trace {
+				trace := []map[string]interface{}{}
+				for _, frame := range err.Caller().Trace() {
+					trace = append(trace, locationJSON(map[string]interface{}{}, frame))
+				}
+				if 0 < len(trace) {
+					data["trace"] = trace
+				}
+			}
+		}
+	case withCaller:
 		if ok && nil != err.Caller() {
 			data["caller"] = fmt.Sprintf("#%d %s:%d (%s)",
 				key,
 				path.Base(err.Caller().File()),
 				err.Caller().Line(),
-				runtime.FuncForPC(err.Caller().Pc()).Name(),
+				err.Caller().Func(),
 			)
 		} else {
 			data["caller"] = fmt.Sprintf("#%d n/a",
 				key,
 			)
 		}
-		// Guarded on ok: list() includes ANY error implementing Unwrap() error, so a foreign
-		// wrapper in the chain -- fmt.Errorf("%w") being the common one -- lands here with err
-		// nil, and reading err.prev panicked. Marshalling an error must never panic; that is the
-		// path a logger takes while already handling a failure.
-		if ok {
-			lastE = err.prev
-		} else {
-			lastE = nil
+	}
+	// frameMessage, not Error(): each entry is one frame, and Error() now carries the wrapped
+	// chain -- so using it here would repeat the whole tail in every entry of the array.
+	policy := redactionPolicy()
+	if "" != frameMessage(nextE) {
+		data["error"] = policy.message(frameMessage(nextE))
+	}
+	if ok && "" != err.code {
+		data["code"] = err.code
+	}
+	if ok && nil != err.kind {
+		data["kind"] = err.kind.Name()
+	}
+	if ok && 0 < len(err.fields) {
+		data["fields"] = policy.fields(err.fields)
+	}
+	if ok && "" != err.id {
+		data["id"] = err.id
+	}
+	if ok && "" != err.public {
+		data["public"] = policy.message(err.public)
+	}
+	if ok && err.remote {
+		data["remote"] = true
+	}
+	return data
+}
+
+// locationJSON adds the JSONSchemaV2 location members of clr to data, and returns data.
+func locationJSON(data map[string]interface{}, clr std_caller.Caller) map[string]interface{} {
+	data["file"] = clr.File()
+	data["base"] = path.Base(clr.File())
+	data["line"] = clr.Line()
+	data["function"] = clr.Func()
+	data["package"] = funcPackage(clr.Func())
+	return data
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It decodes the output of MarshalJSON, or
+// of the %#-v and %#+v verbs, into an equivalent chain, as FromJSON does.
+func (e *E) UnmarshalJSON(data []byte) error {
+	decoded, err := FromJSON(data)
+	if nil != err {
+		return err
+	}
+	if nil == decoded {
+		*e = E{}
+		return nil
+	}
+	*e = *decoded
+	return nil
+}
+
+// FromJSON rebuilds an error chain from the output of MarshalJSON, or of the %#-v and %#+v verbs. It
+// returns nil for JSON null or an empty array.
+//
+// The chain is rebuilt with FromFrames, so every link is marked as remote, and its Error, %+v and
+// JSON output match the original's. Every JSONSchema is accepted: in JSONSchemaV1 callers are parsed
+// from the "#0 file.go:12 (pkg.Func)" strings, and "#0 n/a" is a link with no caller data; in
+// JSONSchemaV2 they are read from the location members, and a trace array, which a remote caller
+// cannot hold, is ignored. The "causes" of an error with several are rebuilt as a join of them, see
+// Join.
+//
+// A single object, rather than an array of them, is accepted as a chain of one. Field values come
+// back as the types encoding/json decodes into: a number is a float64 however it was attached.
+func FromJSON(data []byte) (*E, error) {
+	data = bytes.TrimSpace(data)
+	if 0 < len(data) && '{' == data[0] {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+
+	objects := []frameObject{}
+	if err := json.Unmarshal(data, &objects); nil != err {
+		return nil, Wrap(err, "errors: decoding JSON")
+	}
+	if 0 == len(objects) {
+		return nil, nil
+	}
+	frames := make([]Frame, 0, len(objects))
+	for key, object := range objects {
+		frame := Frame{
+			Message: object.Error,
+			Code:    object.Code,
+			Kind:    object.Kind,
+			Fields:  object.Fields,
+			Public:  object.Public,
+			ID:      object.ID,
+			Remote:  object.Remote,
 		}
-		// frameMessage, not Error(): each entry is one frame, and Error() now carries the wrapped
-		// chain -- so using it here would repeat the whole tail in every entry of the array.
-		if "" != frameMessage(nextE) {
-			data["error"] = frameMessage(nextE)
+		if "" != object.File || "" != object.Function {
+			frame.File, frame.Line, frame.Func = object.File, object.Line, object.Function
+		} else if "" != object.Caller {
+			var ok bool
+			if frame.File, frame.Line, frame.Func, ok = parseCaller(object.Caller); !ok {
+				return nil, Errorf("errors: decoding JSON: frame %d: malformed caller %q", key, object.Caller)
+			}
 		}
-		jsonData = append(jsonData, data)
+		frames = append(frames, frame)
 	}
+	e := FromFrames(frames)
 
-	if nil != lastE {
-		data := map[string]interface{}{}
-		err, ok := lastE.(*E)
-		if ok && nil != err.Caller() {
-			data["caller"] = fmt.Sprintf("#%d %s:%d (%s)",
-				key+1,
-				path.Base(err.Caller().File()),
-				err.Caller().Line(),
-				runtime.FuncForPC(err.Caller().Pc()).Name(),
-			)
-		} else {
-			data["caller"] = fmt.Sprintf("#%d n/a",
-				key+1,
-			)
+	// The branches of an error with several causes, see chainJSON. Only the last frame can have
+	// any: an error that wraps several has no single error to unwrap to.
+	if causes := objects[len(objects)-1].Causes; 0 < len(causes) {
+		branches := make([]error, 0, len(causes))
+		for i, cause := range causes {
+			branch, err := FromJSON(cause)
+			if nil != err {
+				return nil, Wrap(err, "errors: decoding JSON: frame %d: cause %d", len(objects)-1, i)
+			}
+			if nil != branch {
+				branches = append(branches, branch)
+			}
 		}
-		if "" != frameMessage(lastE) {
-			data["error"] = frameMessage(lastE)
+		last := e
+		for nil != last.prev {
+			last = last.prev.(*E)
 		}
-		jsonData = append(jsonData, data)
+		last.prev = &joined{errs: branches}
 	}
+	return e, nil
+}
 
-	return json.Marshal(jsonData)
+// frameObject is the decoded form of one frameJSON object.
+type frameObject struct {
+	Caller   string                 `json:"caller"`
+	File     string                 `json:"file"`
+	Line     int                    `json:"line"`
+	Function string                 `json:"function"`
+	Error    string                 `json:"error"`
+	Code     Code                   `json:"code"`
+	Kind     string                 `json:"kind"`
+	Fields   map[string]interface{} `json:"fields"`
+	Public   string                 `json:"public"`
+	ID       string                 `json:"id"`
+	Remote   bool                   `json:"remote"`
+	Causes   []json.RawMessage      `json:"causes"`
+}
+
+var (
+	callerString   = regexp.MustCompile(`^#\d+ (.*):(\d+) \((.*)\)$`)
+	noCallerString = regexp.MustCompile(`^#\d+ n/a$`)
+)
+
+// parseCaller parses a caller string as frameJSON writes it, "#0 file.go:12 (pkg.Func)", into its
+// parts. "#0 n/a" has none, and is not an error.
+func parseCaller(str string) (file string, line int, fn string, ok bool) {
+	if noCallerString.MatchString(str) {
+		return "", 0, "", true
+	}
+	match := callerString.FindStringSubmatch(str)
+	if nil == match {
+		return "", 0, "", false
+	}
+	line, err := strconv.Atoi(match[2])
+	if nil != err {
+		return "", 0, "", false
+	}
+	return match[1], line, match[3], true
 }
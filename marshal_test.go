@@ -20,7 +20,7 @@ func TestMarshalJSON(t *testing.T) {
 
 	assert.Nil(jsonerr, "jsonerr is not nil")
 	assert.Equal(
-		"[{\"caller\":\"#0 marshal_test.go:18 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 3\"},{\"caller\":\"#1 marshal_test.go:17 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 2\"},{\"caller\":\"#2 marshal_test.go:16 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 1\"}]",
+		"[{\"caller\":\"#0 marshal_test.go:18 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 3\",\"fingerprint\":\"dba10bb6f59e36ca3e956206b51e6ac6\"},{\"caller\":\"#1 marshal_test.go:17 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 2\"},{\"caller\":\"#2 marshal_test.go:16 (github.com/bdlm/errors/v2_test.TestMarshalJSON)\",\"error\":\"test 1\"}]",
 		string(byts),
 		"JSON did not encode properly",
 	)
@@ -0,0 +1,136 @@
+/*
+Package otel records error chains built with github.com/bdlm/errors on OpenTelemetry spans, without
+depending on the OpenTelemetry SDK.
+
+NewRecorder adapts a Span -- two methods taking only basic types -- to errors.Recorder. It records
+each error as OpenTelemetry's semantic conventions record an exception: an "exception" event with
+the attributes errors.ExceptionAttributes builds, and an error status. A trace.Span needs a few
+lines of glue, written once, to be a Span:
+
+	type span struct{ trace.Span }
+
+	func (s span) AddEvent(name string, attrs []errors_otel.Attribute) {
+		kvs := make([]attribute.KeyValue, 0, len(attrs))
+		for _, attr := range attrs {
+			switch v := attr.Value.(type) {
+			case string:
+				kvs = append(kvs, attribute.String(attr.Key, v))
+			case bool:
+				kvs = append(kvs, attribute.Bool(attr.Key, v))
+			case int64:
+				kvs = append(kvs, attribute.Int64(attr.Key, v))
+			case float64:
+				kvs = append(kvs, attribute.Float64(attr.Key, v))
+			}
+		}
+		s.Span.AddEvent(name, trace.WithAttributes(kvs...))
+	}
+
+	func (s span) SetError(description string) {
+		s.Span.SetStatus(codes.Error, description)
+	}
+
+after which recording an error is one line:
+
+	errors.Record(errors_otel.NewRecorder(span{trace.SpanFromContext(ctx)}), err)
+*/
+package otel
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// EventName is the name of the span event recording an exception.
+const EventName = "exception"
+
+// Attribute is an attribute of the exception event. Its Value is one of the types an OpenTelemetry
+// attribute can hold that Attributes produces: a string, bool, int64 or float64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is the part of a tracing span the Recorder uses, see the package documentation.
+type Span interface {
+	// AddEvent adds an event with attrs to the span.
+	AddEvent(name string, attrs []Attribute)
+	// SetError sets the span's status to error, with description.
+	SetError(description string)
+}
+
+// NewRecorder returns an errors.Recorder recording on span. Each error is an EventName event with
+// its attributes converted by Attributes, and sets the span's status to error with the exception
+// message as its description.
+func NewRecorder(span Span) errors.Recorder {
+	return &recorder{span: span}
+}
+
+type recorder struct {
+	span Span
+}
+
+func (r *recorder) RecordError(err error, attrs map[string]interface{}) {
+	if nil == err {
+		return
+	}
+	r.span.AddEvent(EventName, Attributes(attrs))
+	description, _ := attrs["exception.message"].(string)
+	r.span.SetError(description)
+}
+
+// Attributes converts attrs to a list sorted by key, its values converted to the types an
+// OpenTelemetry attribute can hold: a string, bool, int64 or float64. An integer is an int64, an
+// unsigned integer too large for one is formatted as a string, a float32 is a float64, and any other
+// value -- a slice, a map, a struct -- is formatted as a string with %v.
+func Attributes(attrs map[string]interface{}) []Attribute {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ret := make([]Attribute, 0, len(attrs))
+	for _, k := range keys {
+		ret = append(ret, Attribute{Key: k, Value: value(attrs[k])})
+	}
+	return ret
+}
+
+// value converts v to one of the types an Attribute holds.
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint:
+		return unsigned(uint64(v))
+	case uint64:
+		return unsigned(v)
+	case float32:
+		return float64(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// unsigned converts v to an int64 if it fits in one, or a string if it does not.
+func unsigned(v uint64) interface{} {
+	if 1<<63-1 < v {
+		return fmt.Sprint(v)
+	}
+	return int64(v)
+}
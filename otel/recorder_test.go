@@ -0,0 +1,70 @@
+package otel_test
+
+import (
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	errors_otel "github.com/bdlm/errors/v2/otel"
+)
+
+// memorySpan is a Span that keeps what is recorded on it.
+type memorySpan struct {
+	events []string
+	attrs  [][]errors_otel.Attribute
+	status string
+}
+
+func (s *memorySpan) AddEvent(name string, attrs []errors_otel.Attribute) {
+	s.events = append(s.events, name)
+	s.attrs = append(s.attrs, attrs)
+}
+
+func (s *memorySpan) SetError(description string) {
+	s.status = description
+}
+
+func TestRecorder(t *testing.T) {
+	span := &memorySpan{}
+	err := errors.Wrap(errors.New("no row"), "loading the account").WithField("attempt", 3).WithField("shard", uint8(2))
+	errors.Record(errors_otel.NewRecorder(span), err)
+
+	if 1 != len(span.events) || errors_otel.EventName != span.events[0] {
+		t.Fatalf("events = %v, want one exception", span.events)
+	}
+	if err.Error() != span.status {
+		t.Errorf("status = %q, want the exception message", span.status)
+	}
+	attrs := map[string]interface{}{}
+	for i, attr := range span.attrs[0] {
+		if 0 < i && span.attrs[0][i-1].Key >= attr.Key {
+			t.Errorf("attributes are not sorted: %v", span.attrs[0])
+		}
+		attrs[attr.Key] = attr.Value
+	}
+	if int64(3) != attrs["error.field.attempt"] || int64(2) != attrs["error.field.shard"] {
+		t.Errorf("attributes = %v, want the integers as int64", attrs)
+	}
+	if _, ok := attrs["exception.stacktrace"].(string); !ok {
+		t.Errorf("attributes = %v, want a stack trace", attrs)
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	for _, tc := range []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{"x", "x"},
+		{true, true},
+		{int32(-1), int64(-1)},
+		{uint64(1 << 63), "9223372036854775808"},
+		{float32(0.5), float64(0.5)},
+		{[]string{"a", "b"}, "[a b]"},
+		{errors.NewSecret("tok"), errors.Redacted},
+	} {
+		got := errors_otel.Attributes(map[string]interface{}{"k": tc.value})
+		if 1 != len(got) || tc.want != got[0].Value {
+			t.Errorf("Attributes(%#v) = %#v, want %#v", tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	std_errors "errors"
+	"fmt"
+	"runtime"
+)
+
+// Kinds of a recovered panic, see FromPanic. Both are kinds of KindInternal, so an integration that
+// only knows the canonical kinds reports a panic as an internal error.
+var (
+	// KindPanic is the kind of every error FromPanic returns.
+	KindPanic = NewKind("panic", KindInternal)
+	// KindRuntimePanic is the kind of an error FromPanic returns for a runtime.Error: a nil
+	// dereference, an index out of range, a failed type assertion and the like. Those are bugs,
+	// where a panic with any other value may be a deliberate, if unusual, way to fail.
+	KindRuntimePanic = NewKind("runtime_panic", KindPanic)
+)
+
+// FromPanic returns an error for a value returned by recover, or nil if the value is nil.
+//
+// Called while the panic is unwinding -- from a deferred function, directly or not -- its caller
+// trace is the stack at the panic: the function that panicked and its callers, not the deferred
+// function that recovered it and not the runtime's own frames. Called any other way, once the
+// stack has unwound, its caller is where it was called, as for New.
+//
+// A panic value that is an error stays on the chain, so Is and As still find it: a runtime.Error
+// can be recovered with As. Its kind is KindPanic, or KindRuntimePanic for a runtime.Error.
+//
+//	defer func() {
+//		if err := errors.FromPanic(recover()); nil != err {
+//			log.Printf("%+v", err)
+//		}
+//	}()
+func FromPanic(v interface{}) *E {
+	if nil == v {
+		return nil
+	}
+	clr := newCaller(3)
+	clr.panicking = true
+
+	e := &E{
+		caller: clr,
+		kind:   KindPanic,
+	}
+	if err, ok := v.(error); ok {
+		e.err = std_errors.New("panic")
+		e.prev = err
+		var runtimeErr runtime.Error
+		if As(err, &runtimeErr) {
+			e.kind = KindRuntimePanic
+		}
+	} else {
+		e.err = std_errors.New(fmt.Sprintf("panic: %v", v))
+	}
+	return e
+}
+
+// Recover recovers a panic into *err, for use in defer:
+//
+//	func work() (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+//
+// It must be deferred itself, not called from a deferred function, since recover only stops a
+// panic when called by the deferred function directly. The error is FromPanic's and replaces
+// whatever *err held. Without a panic, *err is left alone.
+func Recover(err *error) {
+	if r := recover(); nil != r {
+		*err = FromPanic(r)
+	}
+}
@@ -0,0 +1,99 @@
+package errors_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func writeNilMap() {
+	var m map[string]int
+	m["x"] = 1
+}
+
+func panicWith(v interface{}) {
+	panic(v)
+}
+
+// recovering calls fn and returns FromPanic of whatever it panicked with.
+func recovering(fn func()) (err *errors.E) {
+	defer func() {
+		err = errors.FromPanic(recover())
+	}()
+	fn()
+	return nil
+}
+
+func TestFromPanic(t *testing.T) {
+	err := recovering(func() { panicWith("boom") })
+	if nil == err || "panic: boom" != err.Error() {
+		t.Fatalf("FromPanic = %v", err)
+	}
+	if !errors.Is(err, errors.KindPanic) || errors.Is(err, errors.KindRuntimePanic) || !errors.Is(err, errors.KindInternal) {
+		t.Errorf("kind = %s, want panic", errors.KindOf(err).Name())
+	}
+	trace := funcs(errors.Caller(err).Trace())
+	if 2 > len(trace) || !strings.HasSuffix(trace[0], ".panicWith") || !strings.HasSuffix(trace[1], ".TestFromPanic.func1") {
+		t.Errorf("trace = %v, want it to start at the panic", trace)
+	}
+	for _, fn := range trace {
+		if strings.HasPrefix(fn, "runtime.") && "runtime.goexit" != fn {
+			t.Errorf("the trace includes the runtime's frame %s: %v", fn, trace)
+		}
+		if strings.HasSuffix(fn, ".recovering.func1") {
+			t.Errorf("the trace includes the recovering function: %v", trace)
+		}
+	}
+}
+
+func TestFromPanicKeepsAnErrorValue(t *testing.T) {
+	err := recovering(func() { panicWith(errors.Wrap(sentinel, "invariant")) })
+	if !errors.Is(err, sentinel) {
+		t.Error("the panicked error is not on the chain")
+	}
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".panicWith") {
+		t.Errorf("caller is %s, want the panic", errors.Caller(err).Func())
+	}
+}
+
+func TestFromPanicClassifiesRuntimeErrors(t *testing.T) {
+	err := recovering(writeNilMap)
+	if !errors.Is(err, errors.KindRuntimePanic) {
+		t.Errorf("kind = %s, want runtime_panic", errors.KindOf(err).Name())
+	}
+	var runtimeErr runtime.Error
+	if !errors.As(err, &runtimeErr) {
+		t.Error("the runtime.Error is not on the chain")
+	}
+	if clr := errors.Caller(err); !strings.HasSuffix(clr.Func(), ".writeNilMap") {
+		t.Errorf("caller is %s, want the faulting function", clr.Func())
+	}
+}
+
+func TestFromPanicWithoutAPanic(t *testing.T) {
+	if nil != errors.FromPanic(nil) {
+		t.Error("FromPanic(nil) is not nil")
+	}
+	// Once the stack has unwound there is no panic to start at, so the caller is the call site.
+	if err := errors.FromPanic("late"); !strings.HasSuffix(errors.Caller(err).Func(), ".TestFromPanicWithoutAPanic") {
+		t.Errorf("caller is %s, want the call site", errors.Caller(err).Func())
+	}
+}
+
+func recoverInto(fn func()) (err error) {
+	defer errors.Recover(&err)
+	fn()
+	return sentinel
+}
+
+func TestRecover(t *testing.T) {
+	err := recoverInto(func() { panicWith("boom") })
+	if !errors.Is(err, errors.KindPanic) || !strings.HasSuffix(errors.Caller(err).Func(), ".panicWith") {
+		t.Errorf("Recover = %+v", err)
+	}
+	if err := recoverInto(func() {}); sentinel != err {
+		t.Errorf("without a panic the result was changed: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	std_errors "errors"
+)
+
+// DefaultPublicMessage is the public message of an error that was given none, see PublicMessage.
+const DefaultPublicMessage = "an internal error occurred"
+
+// View selects which of an error's messages a rendering shows, see Message.
+//
+// An error has two audiences. The full message, "outer: inner: ...", is written for whoever
+// maintains the program: it names the internals that failed, which is what a log needs and what a
+// user must not see. The public message is written for the user, and is set explicitly, with
+// WithPublic or NewPublic, by the code that knows the failure is safe to describe.
+//
+// Format, MarshalJSON and LogAttrs take no View: they render the chain frame by frame, which is the
+// internal view by definition. Output for a user asks for either view with Message.
+type View int
+
+const (
+	// PublicView is the user-safe message, see PublicMessage. It is the zero View, so an
+	// integration that is not told otherwise shows nothing internal.
+	PublicView View = iota
+	// InternalView is the full message, see (*E).Error.
+	InternalView
+)
+
+// NewPublic returns an error, as New does, whose message is also its public message: for a failure
+// that is the user's to fix, where the message written for the log is the one to show them too.
+//
+//	return errors.NewPublic("the name must not be empty").WithKind(errors.KindInvalidArgument)
+func NewPublic(msg string) *E {
+	return &E{
+		caller: newCallerSkip(0),
+		err:    std_errors.New(msg),
+		public: msg,
+	}
+}
+
+// WithPublic returns a copy of this frame carrying msg as its public message, the message shown to
+// a user in place of the internal one, see PublicMessage. An empty msg removes it.
+//
+//	return errors.Wrap(err, "inserting the order row").WithPublic("the order could not be saved")
+func (e *E) WithPublic(msg string) *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.public = msg
+	return cp
+}
+
+// PublicMessage returns the outermost public message in err's chain, or DefaultPublicMessage if no
+// link has one. It never returns any other message the chain carries, so it is safe to show to
+// anyone err reaches, and is redacted by the policy set with SetRedactionPolicy besides.
+//
+// The chain is searched the same way CodeOf searches it. The outermost public message wins because
+// it was set by the code that knew the most about what the failure means to the user.
+func PublicMessage(err error) string {
+	if msg, ok := LookupPublicMessage(err); ok {
+		return msg
+	}
+	return DefaultPublicMessage
+}
+
+// LookupPublicMessage returns the outermost public message in err's chain, as PublicMessage does,
+// and whether there is one at all, for a caller with a better default of its own.
+func LookupPublicMessage(err error) (string, bool) {
+	var msg string
+	found := walk(err, func(link error) bool {
+		if e, ok := link.(*E); ok && nil != e && "" != e.public {
+			msg = e.public
+			return true
+		}
+		return false
+	})
+	return RedactMessage(msg), found
+}
+
+// Message returns err's message in the given view: its public message, see PublicMessage, or its
+// full message, see (*E).Error. Either is redacted by the policy set with SetRedactionPolicy. It
+// returns the empty string for a nil error.
+func Message(err error, view View) string {
+	if nil == err {
+		return ""
+	}
+	if InternalView == view {
+		return RedactMessage(err.Error())
+	}
+	return PublicMessage(err)
+}
@@ -0,0 +1,78 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestPublicMessage(t *testing.T) {
+	inner := errors.New("pq: relation \"users\" does not exist").WithPublic("the user could not be loaded")
+	outer := errors.Wrap(errors.Wrap(inner, "querying users"), "loading the profile").WithPublic("the profile is unavailable")
+
+	for name, tc := range map[string]struct {
+		err  error
+		want string
+	}{
+		"nil":              {nil, errors.DefaultPublicMessage},
+		"none":             {errors.Wrap(errors.New("x"), "y"), errors.DefaultPublicMessage},
+		"foreign":          {std_errors.New("x"), errors.DefaultPublicMessage},
+		"outermost wins":   {outer, "the profile is unavailable"},
+		"inner":            {errors.Wrap(inner, "handling the request"), "the user could not be loaded"},
+		"fmt wrapped":      {fmt.Errorf("f: %w", inner), "the user could not be loaded"},
+		"join branch":      {errors.Join(errors.New("x"), inner), "the user could not be loaded"},
+		"removed":          {inner.WithPublic(""), errors.DefaultPublicMessage},
+		"NewPublic":        {errors.NewPublic("the name must not be empty"), "the name must not be empty"},
+		"WrapE annotation": {errors.WrapE(errors.New("cause"), errors.NewPublic("try again later")), "try again later"},
+	} {
+		if got := errors.PublicMessage(tc.err); tc.want != got {
+			t.Errorf("%s: PublicMessage = %q, want %q", name, got, tc.want)
+		}
+	}
+
+	if _, ok := errors.LookupPublicMessage(errors.New("x")); ok {
+		t.Error("LookupPublicMessage found a public message where there is none")
+	}
+	if "querying users: pq: relation \"users\" does not exist" != errors.Wrap(inner, "querying users").Error() {
+		t.Error("the public message changed the internal one")
+	}
+}
+
+func TestNewPublicCaller(t *testing.T) {
+	err := errors.NewPublic("x")
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestNewPublicCaller") || "x" != err.Error() {
+		t.Errorf("NewPublic = %q at %s", err.Error(), errors.Caller(err).Func())
+	}
+}
+
+func TestMessage(t *testing.T) {
+	err := errors.Wrap(errors.New("no row"), "loading the account").WithPublic("no such account")
+	if got := errors.Message(err, errors.PublicView); "no such account" != got {
+		t.Errorf("public view = %q", got)
+	}
+	if got := errors.Message(err, errors.InternalView); err.Error() != got {
+		t.Errorf("internal view = %q", got)
+	}
+	if "" != errors.Message(nil, errors.PublicView) {
+		t.Error("a nil error has a message")
+	}
+}
+
+func TestPublicMessageSurvivesJSONAndFrames(t *testing.T) {
+	err := errors.Wrap(errors.New("no row").WithPublic("no such account"), "loading the account")
+	byts, _ := json.Marshal(err)
+	if !strings.Contains(string(byts), `"public":"no such account"`) {
+		t.Errorf("JSON = %s, want the public message", byts)
+	}
+	decoded, decodeErr := errors.FromJSON(byts)
+	if nil != decodeErr || "no such account" != errors.PublicMessage(decoded) {
+		t.Errorf("FromJSON = %v, %v", decoded, decodeErr)
+	}
+	if "no such account" != errors.PublicMessage(errors.FromFrames(errors.Frames(err))) {
+		t.Error("the public message did not survive Frames and FromFrames")
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	std_caller "github.com/bdlm/std/v2/caller"
+)
+
+// Recorder records errors with a tracing system, typically as an event on the current span.
+//
+// The interface is this package's own so that the package does not depend on any tracing SDK: the
+// otel subpackage adapts it to OpenTelemetry's shape, and a test can record into memory.
+type Recorder interface {
+	// RecordError records err with attrs, as ExceptionAttributes builds them.
+	RecordError(err error, attrs map[string]interface{})
+}
+
+// Record records err with r, with the attributes ExceptionAttributes builds for it. It does nothing
+// for a nil error or Recorder.
+func Record(r Recorder, err error) {
+	if nil == r || nil == err {
+		return
+	}
+	r.RecordError(err, ExceptionAttributes(err))
+}
+
+// ExceptionAttributes returns the attributes describing err as the OpenTelemetry semantic
+// conventions describe an exception, plus the structured context this package adds to it:
+//
+//	exception.type        the Go type of the innermost error in the chain, its root cause
+//	exception.message     err.Error()
+//	exception.stacktrace  the trace of the innermost link with caller data, see Caller, formatted as
+//	                      a Go stack trace is
+//	error.code            the nearest code in the chain, see CodeOf
+//	error.kind            the name of the nearest kind in the chain, see KindOf
+//	error.id              the outermost instance ID in the chain, see ID
+//	error.fingerprint     the fingerprint of the chain, see Fingerprint
+//	error.field.<key>     each of the chain's fields, see Fields
+//
+// Attributes without a value are left out. The message and fields are redacted by the policy set
+// with SetRedactionPolicy, since a span leaves the process. It returns nil for a nil error.
+func ExceptionAttributes(err error) map[string]interface{} {
+	if nil == err {
+		return nil
+	}
+	attrs := map[string]interface{}{
+		"exception.type":    typeName(root(err)),
+		"exception.message": RedactMessage(err.Error()),
+		"error.fingerprint": Fingerprint(err),
+	}
+	if stack := stacktrace(err); "" != stack {
+		attrs["exception.stacktrace"] = stack
+	}
+	if code := CodeOf(err); "" != code {
+		attrs["error.code"] = string(code)
+	}
+	if kind := KindOf(err); nil != kind {
+		attrs["error.kind"] = kind.Name()
+	}
+	if id := ID(err); "" != id {
+		attrs["error.id"] = id
+	}
+	for k, v := range RedactFields(Fields(err)) {
+		attrs["error.field."+k] = v
+	}
+	return attrs
+}
+
+// root returns the innermost error of err's Unwrap chain.
+func root(err error) error {
+	for next := Unwrap(err); nil != next; next = Unwrap(next) {
+		err = next
+	}
+	return err
+}
+
+// typeName is the fully qualified name of err's type, "*io/fs.PathError" rather than the
+// "*fs.PathError" %T gives, so that types from packages of the same name can be told apart.
+func typeName(err error) string {
+	typ := reflect.TypeOf(err)
+	prefix := ""
+	for reflect.Ptr == typ.Kind() {
+		prefix += "*"
+		typ = typ.Elem()
+	}
+	if "" == typ.PkgPath() || "" == typ.Name() {
+		return prefix + typ.String()
+	}
+	return prefix + typ.PkgPath() + "." + typ.Name()
+}
+
+// stacktrace formats the trace of the innermost link of err with caller data as runtime/debug.Stack
+// does, a function per line followed by its indented location, innermost call first. It returns
+// the empty string if no link has caller data.
+func stacktrace(err error) string {
+	var clr std_caller.Caller
+	for link := err; nil != link; link = Unwrap(link) {
+		if e, ok := link.(*E); ok && nil != e && nil != e.Caller() {
+			clr = e.Caller()
+		}
+	}
+	if nil == clr {
+		return ""
+	}
+	trace := clr.Trace()
+	if 0 == len(trace) {
+		trace = std_caller.Trace{clr}
+	}
+	var str strings.Builder
+	for _, frame := range trace {
+		fmt.Fprintf(&str, "%s()\n\t%s:%d\n", frame.Func(), frame.File(), frame.Line())
+	}
+	return str.String()
+}
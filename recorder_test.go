@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// memoryRecorder is an errors.Recorder that keeps what it records.
+type memoryRecorder struct {
+	errs  []error
+	attrs []map[string]interface{}
+}
+
+func (r *memoryRecorder) RecordError(err error, attrs map[string]interface{}) {
+	r.errs = append(r.errs, err)
+	r.attrs = append(r.attrs, attrs)
+}
+
+func openConfig() error {
+	_, err := os.Open("/nonexistent/config.yaml")
+	return errors.Wrap(err, "opening the configuration").WithCode("config_missing").WithKind(errors.KindNotFound)
+}
+
+func TestRecord(t *testing.T) {
+	err := errors.Wrap(openConfig(), "starting").WithField("attempt", 2).WithField("password", "hunter2").WithID()
+	rec := &memoryRecorder{}
+	errors.Record(rec, err)
+
+	if 1 != len(rec.errs) || err != rec.errs[0] {
+		t.Fatalf("recorded %v, want the error once", rec.errs)
+	}
+	attrs := rec.attrs[0]
+	for key, want := range map[string]interface{}{
+		"exception.type":       "syscall.Errno",
+		"exception.message":    err.Error(),
+		"error.code":           "config_missing",
+		"error.kind":           "not_found",
+		"error.id":             errors.ID(err),
+		"error.fingerprint":    errors.Fingerprint(err),
+		"error.field.attempt":  2,
+		"error.field.password": errors.Redacted,
+	} {
+		if want != attrs[key] {
+			t.Errorf("%s = %#v, want %#v", key, attrs[key], want)
+		}
+	}
+	stack, _ := attrs["exception.stacktrace"].(string)
+	lines := strings.Split(stack, "\n")
+	if 2 > len(lines) || !strings.HasSuffix(lines[0], ".openConfig()") || !strings.HasPrefix(lines[1], "\t") ||
+		!strings.Contains(lines[1], "recorder_test.go:") {
+		t.Errorf("exception.stacktrace = %q, want the trace of the innermost frame", stack)
+	}
+}
+
+func TestRecordNothing(t *testing.T) {
+	rec := &memoryRecorder{}
+	errors.Record(rec, nil)
+	errors.Record(nil, errors.New("x"))
+	if 0 != len(rec.errs) {
+		t.Errorf("recorded %v", rec.errs)
+	}
+	if nil != errors.ExceptionAttributes(nil) {
+		t.Error("a nil error has attributes")
+	}
+	attrs := errors.ExceptionAttributes(os.ErrNotExist)
+	if _, ok := attrs["exception.stacktrace"]; ok || "*errors.errorString" != attrs["exception.type"] {
+		t.Errorf("attributes of a foreign error = %v", attrs)
+	}
+}
@@ -0,0 +1,257 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Redacted is what a redacted value renders as.
+const Redacted = "<redacted>"
+
+// Secret is a value that must never be rendered: a token, a password, a card number.
+//
+// It renders as Redacted through every fmt verb, and so through Errorf, Wrap and anything else that
+// formats its arguments, as well as through encoding/json and log/slog. The value itself is only
+// available from Reveal, so a secret cannot leak by being formatted -- only by being asked for.
+//
+//	return errors.Wrap(err, "rejecting token %s", errors.NewSecret(token))
+type Secret struct {
+	value interface{}
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value interface{}) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the value the Secret holds.
+func (s Secret) Reveal() interface{} {
+	return s.value
+}
+
+// String implements fmt.Stringer.
+func (s Secret) String() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter, for every verb and flag.
+func (s Secret) Format(state fmt.State, verb rune) {
+	_, _ = io.WriteString(state, Redacted)
+}
+
+// MarshalJSON implements the json.Marshaller interface.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// RedactionPolicy describes the sensitive data that is redacted when an error is rendered, for the
+// values no one thought to wrap in a Secret.
+//
+// The policy is applied to what leaves the process or reaches a user: MarshalJSON and the %#v
+// verbs, LogAttrs, PublicMessage and Message, and the grpc and http subpackages. Text applies it to
+// the other fmt verbs as well. Error is never redacted: it is the error's message, not a rendering
+// of it, and code matching on it must see what was written.
+type RedactionPolicy struct {
+	// Patterns match sensitive text in messages and in string field values. Each match is replaced
+	// with Redacted.
+	Patterns []*regexp.Regexp
+	// Cards redacts card numbers in messages and in string field values: runs of 13 to 19 digits,
+	// optionally grouped by spaces or dashes, that pass the Luhn check. An order number or a
+	// timestamp of the same length almost never does.
+	Cards bool
+	// Fields are the names of sensitive fields, see WithFields. The value of a field is replaced with
+	// Redacted if one of them matches whole segments of its name, ignoring case, where a name is
+	// split into segments at underscores, dashes, dots, spaces and changes from lower to upper case:
+	// "token" matches "access_token" and "apiToken" but not "tokenizer", and "session_id" matches
+	// "userSessionID" but not "session_count".
+	Fields []string
+	// Text applies the policy to %s, %v, %q and the trace verbs, which are usually only logged.
+	Text bool
+
+	// fieldSegments are Fields split into segments, see SetRedactionPolicy.
+	fieldSegments [][]string
+}
+
+// DefaultRedactionPolicy returns the policy in effect until SetRedactionPolicy is called: email
+// addresses, card numbers, bearer tokens and JWTs in messages, and fields named like credentials,
+// card details or contact details, in everything but the text formats.
+func DefaultRedactionPolicy() RedactionPolicy {
+	return RedactionPolicy{
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+			regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*`),
+			regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		},
+		Cards: true,
+		Fields: []string{
+			"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie",
+			"session_id", "sessionid", "credential", "credentials", "email", "card_number",
+			"cardnumber", "cvv", "cvc", "ssn",
+		},
+	}
+}
+
+var redactionPolicyValue atomic.Value
+
+func init() {
+	SetRedactionPolicy(DefaultRedactionPolicy())
+}
+
+// SetRedactionPolicy sets the policy applied to every error rendered from now on. The zero policy
+// redacts nothing but Secret values, which are always redacted.
+func SetRedactionPolicy(policy RedactionPolicy) {
+	cp := RedactionPolicy{
+		Patterns:      append([]*regexp.Regexp(nil), policy.Patterns...),
+		Cards:         policy.Cards,
+		Fields:        append([]string(nil), policy.Fields...),
+		Text:          policy.Text,
+		fieldSegments: make([][]string, 0, len(policy.Fields)),
+	}
+	for _, name := range policy.Fields {
+		if segments := segments(name); 0 < len(segments) {
+			cp.fieldSegments = append(cp.fieldSegments, segments)
+		}
+	}
+	redactionPolicyValue.Store(&cp)
+}
+
+// redactionPolicy returns the policy set with SetRedactionPolicy.
+func redactionPolicy() *RedactionPolicy {
+	return redactionPolicyValue.Load().(*RedactionPolicy)
+}
+
+// RedactMessage returns msg with every match of the policy's patterns, and every card number if the
+// policy redacts them, replaced with Redacted.
+func RedactMessage(msg string) string {
+	return redactionPolicy().message(msg)
+}
+
+// RedactFields returns a copy of fields with the policy applied: the value of a sensitive field is
+// Redacted, and the policy's patterns are redacted from any other string value. It returns nil for
+// a nil map.
+func RedactFields(fields map[string]interface{}) map[string]interface{} {
+	return redactionPolicy().fields(fields)
+}
+
+func (p *RedactionPolicy) message(msg string) string {
+	for _, pattern := range p.Patterns {
+		msg = pattern.ReplaceAllLiteralString(msg, Redacted)
+	}
+	if p.Cards {
+		msg = cardPattern.ReplaceAllStringFunc(msg, func(candidate string) string {
+			if luhn(candidate) {
+				return Redacted
+			}
+			return candidate
+		})
+	}
+	return msg
+}
+
+func (p *RedactionPolicy) fields(fields map[string]interface{}) map[string]interface{} {
+	if nil == fields {
+		return nil
+	}
+	ret := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if p.sensitive(k) {
+			ret[k] = Redacted
+		} else if str, ok := v.(string); ok {
+			ret[k] = p.message(str)
+		} else {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// sensitive reports whether the field named key is one the policy redacts: whether the segments of
+// one of the policy's names appear, in order and next to each other, among the segments of key.
+func (p *RedactionPolicy) sensitive(key string) bool {
+	keySegments := segments(key)
+	for _, name := range p.fieldSegments {
+		for i := 0; i+len(name) <= len(keySegments); i++ {
+			match := true
+			for j, segment := range name {
+				if segment != keySegments[i+j] {
+					match = false
+					break
+				}
+			}
+			if match {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segments splits a field name into lower-case segments, see RedactionPolicy.Fields. A run of upper
+// case letters is one segment, less its last letter if a lower case one follows: "APIKey" is "api"
+// and "key".
+func segments(name string) []string {
+	var ret []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if 0 <= start {
+				ret = append(ret, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if 0 > start {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		if unicode.IsUpper(r) && (!unicode.IsUpper(prev) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			ret = append(ret, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	if 0 <= start {
+		ret = append(ret, strings.ToLower(string(runes[start:])))
+	}
+	return ret
+}
+
+// cardPattern matches the candidates for a card number, which luhn then checks.
+var cardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+// luhn reports whether the digits of candidate, ignoring anything else, pass the Luhn check that
+// every card number does.
+func luhn(candidate string) bool {
+	sum, double := 0, false
+	for i := len(candidate) - 1; 0 <= i; i-- {
+		c := candidate[i]
+		if '0' > c || '9' < c {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if 9 < digit {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return 0 == sum%10
+}
+
+// text returns msg redacted if the policy applies to the text formats.
+func (p *RedactionPolicy) text(msg string) string {
+	if !p.Text {
+		return msg
+	}
+	return p.message(msg)
+}
@@ -0,0 +1,143 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// hasRedacted reports whether rendered shows a redacted value, either as it is or as encoding/json
+// escapes it.
+func hasRedacted(rendered string) bool {
+	return strings.Contains(rendered, errors.Redacted) || strings.Contains(rendered, `\u003credacted\u003e`)
+}
+
+func TestSecret(t *testing.T) {
+	secret := errors.NewSecret("tok-123")
+	err := errors.Wrap(errors.Errorf("rejecting %s", secret), "authenticating %v", secret)
+
+	for name, rendered := range map[string]string{
+		"Error":   err.Error(),
+		"%+v":     fmt.Sprintf("%+v", err),
+		"%#+v":    fmt.Sprintf("%#+v", err),
+		"%x":      fmt.Sprintf("%x", secret),
+		"%#v":     fmt.Sprintf("%#v", secret),
+		"Sprint":  fmt.Sprint(secret),
+		"a field": fmt.Sprintf("%#v", errors.New("x").WithField("key", secret)),
+	} {
+		if strings.Contains(rendered, "tok-123") || !hasRedacted(rendered) {
+			t.Errorf("%s = %s, want the secret redacted", name, rendered)
+		}
+	}
+	if byts, _ := json.Marshal(secret); !hasRedacted(string(byts)) {
+		t.Errorf("JSON = %s", byts)
+	}
+	if "tok-123" != secret.Reveal() {
+		t.Errorf("Reveal = %v", secret.Reveal())
+	}
+}
+
+func TestDefaultRedactionPolicy(t *testing.T) {
+	err := errors.Wrap(
+		errors.New("card 4111 1111 1111 1111 declined for jane@example.com"),
+		"charging with Bearer abc.def",
+	).WithFields(map[string]interface{}{
+		"user_email": "jane@example.com",
+		"API_Token":  "tok-123",
+		"note":       "contact jane@example.com",
+		"attempts":   3,
+	})
+
+	byts, _ := json.Marshal(err)
+	for _, leak := range []string{"4111", "jane@example.com", "abc.def", "tok-123"} {
+		if strings.Contains(string(byts), leak) {
+			t.Errorf("JSON leaks %q: %s", leak, byts)
+		}
+	}
+	if !strings.Contains(string(byts), `"attempts":3`) || !strings.Contains(string(byts), `"note":"contact \u003credacted\u003e"`) {
+		t.Errorf("JSON = %s, want only the sensitive values redacted", byts)
+	}
+	if got := errors.Message(err, errors.InternalView); "charging with <redacted>: card <redacted> declined for <redacted>" != got {
+		t.Errorf("Message = %q", got)
+	}
+	if got := errors.PublicMessage(err.WithPublic("no charge for jane@example.com")); "no charge for <redacted>" != got {
+		t.Errorf("PublicMessage = %q", got)
+	}
+
+	// Off for the text formats, and never applied to Error.
+	if !strings.Contains(fmt.Sprintf("%+v", err), "jane@example.com") || !strings.Contains(err.Error(), "4111") {
+		t.Errorf("the text formats were redacted: %+v", err)
+	}
+}
+
+func TestSetRedactionPolicy(t *testing.T) {
+	errors.SetRedactionPolicy(errors.RedactionPolicy{
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`acct-\d+`)},
+		Fields:   []string{"Tenant"},
+		Text:     true,
+	})
+	defer errors.SetRedactionPolicy(errors.DefaultRedactionPolicy())
+
+	err := errors.Wrap(errors.New("no acct-42"), "loading jane@example.com").WithField("tenant_id", "t-1")
+	for name, rendered := range map[string]string{
+		"%v":   fmt.Sprintf("%v", errors.Trace(err)),
+		"%s":   fmt.Sprintf("%s", err),
+		"%+v":  fmt.Sprintf("%+v", err),
+		"%#+v": fmt.Sprintf("%#+v", err),
+	} {
+		if strings.Contains(rendered, "acct-42") || strings.Contains(rendered, "t-1") {
+			t.Errorf("%s = %s, want it redacted", name, rendered)
+		}
+		if !strings.Contains(rendered, "jane@example.com") {
+			t.Errorf("%s = %s, want only the policy applied", name, rendered)
+		}
+	}
+	if !strings.Contains(err.Error(), "acct-42") {
+		t.Errorf("Error() = %q, want it unredacted", err.Error())
+	}
+
+	errors.SetRedactionPolicy(errors.RedactionPolicy{})
+	if byts, _ := json.Marshal(err); !strings.Contains(string(byts), "acct-42") {
+		t.Errorf("the zero policy redacted %s", byts)
+	}
+}
+
+func TestDefaultRedactionPolicyMatchesWholeSegments(t *testing.T) {
+	err := errors.New("x").WithFields(map[string]interface{}{
+		"session_count":  2,
+		"discard_reason": "stale",
+		"tokenizer":      "bpe",
+		"userSessionID":  "s-1",
+		"access_token":   "tok-1",
+		"APIKey":         "k-1",
+		"card-number":    "c-1",
+	})
+	byts, _ := json.Marshal(err)
+	for _, kept := range []string{`"session_count":2`, `"discard_reason":"stale"`, `"tokenizer":"bpe"`} {
+		if !strings.Contains(string(byts), kept) {
+			t.Errorf("JSON = %s, want %s kept", byts, kept)
+		}
+	}
+	for _, leak := range []string{"s-1", "tok-1", "k-1", "c-1"} {
+		if strings.Contains(string(byts), leak) {
+			t.Errorf("JSON leaks %q: %s", leak, byts)
+		}
+	}
+}
+
+func TestDefaultRedactionPolicyChecksCardNumbers(t *testing.T) {
+	for msg, want := range map[string]string{
+		"card 4111-1111-1111-1111 declined": "card <redacted> declined",
+		"card 5500 0000 0000 0004 declined": "card <redacted> declined",
+		"order 1234567890123 shipped":       "order 1234567890123 shipped",
+		"at 20261017123456789 ms":           "at 20261017123456789 ms",
+	} {
+		if got := errors.RedactMessage(msg); want != got {
+			t.Errorf("RedactMessage(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// retryHint is a frame's own retry classification, see WithRetryable and WithPermanent.
+type retryHint struct {
+	retryable bool
+	after     time.Duration
+}
+
+// WithRetryable returns a copy of this frame marked as retryable: the operation that failed may
+// succeed if it is attempted again. after, if positive, is how long to wait before doing so, as a
+// server's Retry-After says; see RetryAfter. The receiver is not modified, see WithField.
+func (e *E) WithRetryable(after time.Duration) *E {
+	if nil == e {
+		return nil
+	}
+	if 0 > after {
+		after = 0
+	}
+	cp := e.clone()
+	cp.retry = &retryHint{retryable: true, after: after}
+	return cp
+}
+
+// WithPermanent returns a copy of this frame marked as permanent: attempting the operation again
+// will fail the same way. The receiver is not modified, see WithField.
+func (e *E) WithPermanent() *E {
+	if nil == e {
+		return nil
+	}
+	cp := e.clone()
+	cp.retry = &retryHint{}
+	return cp
+}
+
+// RetryClassifier reports whether a single link of a chain is retryable, and ok if it has an
+// opinion about the link at all. See RegisterRetryClassifier.
+type RetryClassifier func(err error) (retryable, ok bool)
+
+var (
+	classifiersMu sync.RWMutex
+	// classifiers is replaced, never modified in place, so a copy of it can be read unlocked.
+	classifiers []*RetryClassifier
+)
+
+// RegisterRetryClassifier adds a classifier IsRetryable and IsPermanent consult for every link of a
+// chain, for error types this package cannot know about. The grpc subpackage registers one that
+// treats a status of codes.Unavailable as retryable, for example.
+//
+// The returned function removes the classifier again, for a test that registers one temporarily;
+// calling it more than once is harmless.
+func RegisterRetryClassifier(classifier RetryClassifier) (unregister func()) {
+	registered := &classifier
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifiers = append(append([]*RetryClassifier{}, classifiers...), registered)
+
+	return func() {
+		classifiersMu.Lock()
+		defer classifiersMu.Unlock()
+		remaining := make([]*RetryClassifier, 0, len(classifiers))
+		for _, classifier := range classifiers {
+			if registered != classifier {
+				remaining = append(remaining, classifier)
+			}
+		}
+		classifiers = remaining
+	}
+}
+
+// IsRetryable reports whether the operation that failed with err may succeed if attempted again.
+//
+// The chain is searched the way CodeOf searches it, and the first link that gives an answer decides,
+// since the code nearest the caller knew the most. For each link, in order of precedence:
+//
+//   - a frame marked with WithRetryable or WithPermanent.
+//   - a classifier added with RegisterRetryClassifier.
+//   - a Temporary() bool or Timeout() bool method returning true, as net.Error has, is retryable.
+//   - a kind of KindUnavailable or KindDeadlineExceeded, or a descendant of either, is retryable.
+//   - context.DeadlineExceeded is retryable, and context.Canceled is permanent: whoever canceled
+//     the operation no longer wants it done.
+//
+// An error nothing gives an answer for is neither retryable nor permanent. A nil error is neither.
+//
+// Classifiers are registered by the packages that know the error types, when they are initialized.
+// In particular, a gRPC status of codes.Unavailable is only retryable in a program that imports
+// the grpc subpackage, which registers its classifier in init; a program that receives status
+// errors without it must import it for its classifier, as
+//
+//	import _ "github.com/bdlm/errors/v2/grpc"
+func IsRetryable(err error) bool {
+	retryable, ok := classify(err)
+	return ok && retryable
+}
+
+// IsPermanent reports whether the operation that failed with err will fail the same way if
+// attempted again. See IsRetryable.
+func IsPermanent(err error) bool {
+	retryable, ok := classify(err)
+	return ok && !retryable
+}
+
+// RetryAfter returns how long to wait before attempting the operation that failed with err again, as
+// set by the nearest frame marked with WithRetryable, or by a RetryAfter() time.Duration method. ok
+// is false if no link in the chain says.
+func RetryAfter(err error) (after time.Duration, ok bool) {
+	walk(err, func(link error) bool {
+		if e, isE := link.(*E); isE && nil != e && nil != e.retry && 0 < e.retry.after {
+			after, ok = e.retry.after, true
+		} else if hint, isHint := link.(interface{ RetryAfter() time.Duration }); isHint && 0 < hint.RetryAfter() {
+			after, ok = hint.RetryAfter(), true
+		}
+		return ok
+	})
+	return after, ok
+}
+
+// classify is IsRetryable and IsPermanent: whether err is retryable, and ok if anything says.
+func classify(err error) (retryable, ok bool) {
+	classifiersMu.RLock()
+	registered := classifiers
+	classifiersMu.RUnlock()
+
+	walk(err, func(link error) bool {
+		if e, isE := link.(*E); isE && nil != e && nil != e.retry {
+			retryable, ok = e.retry.retryable, true
+			return true
+		}
+		for _, classifier := range registered {
+			if retryable, ok = (*classifier)(link); ok {
+				return true
+			}
+		}
+		if temporary, isTemporary := link.(interface{ Temporary() bool }); isTemporary && temporary.Temporary() {
+			retryable, ok = true, true
+			return true
+		}
+		if timeout, isTimeout := link.(interface{ Timeout() bool }); isTimeout && timeout.Timeout() {
+			retryable, ok = true, true
+			return true
+		}
+		var kind *Kind
+		switch link := link.(type) {
+		case *E:
+			kind = link.kindOf()
+		case *Kind:
+			kind = link
+		}
+		if nil != kind && (kind.Is(KindUnavailable) || kind.Is(KindDeadlineExceeded)) {
+			retryable, ok = true, true
+			return true
+		}
+		// comparableErrors first: == panics on an error whose dynamic type is not comparable.
+		if comparableErrors(link, context.DeadlineExceeded) && link == context.DeadlineExceeded {
+			retryable, ok = true, true
+			return true
+		}
+		if comparableErrors(link, context.Canceled) && link == context.Canceled {
+			retryable, ok = false, true
+			return true
+		}
+		return false
+	})
+	return retryable, ok
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// Attempts is the most times the operation is attempted, the first included. Zero or less is 3.
+	Attempts int
+	// Initial is the delay before the second attempt. Zero or less is 100ms.
+	Initial time.Duration
+	// Max caps the delay between attempts. Zero or less is no cap other than the longest
+	// time.Duration.
+	Max time.Duration
+	// Multiplier is the factor each delay grows by. Less than 1 is 2.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it either way, so that clients which
+	// failed together do not retry together. Zero is no jitter, and it is at most 1.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a reasonable policy for a call to another service.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Initial:    100 * time.Millisecond,
+	Max:        10 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// maxDelay is the longest delay a time.Duration can hold, which an uncapped policy reaches after
+// enough attempts. Converting a larger float to a Duration overflows, into a negative delay.
+const maxDelay = float64(math.MaxInt64)
+
+// delay returns the delay before attempt number attempt, counted from 1 for the first retry.
+func (policy RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(policy.Initial)
+	if 0 >= delay {
+		delay = float64(100 * time.Millisecond)
+	}
+	multiplier := policy.Multiplier
+	if 1 > multiplier {
+		multiplier = 2
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if (0 < policy.Max && delay > float64(policy.Max)) || delay >= maxDelay {
+			break
+		}
+	}
+	if 0 < policy.Max && delay > float64(policy.Max) {
+		delay = float64(policy.Max)
+	}
+	if jitter := policy.Jitter; 0 < jitter {
+		if 1 < jitter {
+			jitter = 1
+		}
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+	if delay >= maxDelay {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// Retry calls fn until it succeeds, the policy's attempts are used up, fn fails with an error that
+// IsPermanent, or ctx is done, and returns nil on success.
+//
+// Between attempts it waits with exponential backoff and jitter, as policy says, or for as long as
+// RetryAfter says if the error says. An error that is neither retryable nor permanent is retried:
+// Retry exists for operations that are expected to fail transiently, and most errors do not say.
+//
+// On failure it returns a join of every attempt's error, in order, and ctx's error if that is what
+// ended the attempts, recorded at the call to Retry. See Join. The result is an error rather than
+// an *E, as errgroup's is, so that returning it from a function that returns error returns a nil
+// error on success; the join is an *E all the same.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	attempts := policy.Attempts
+	if 0 >= attempts {
+		attempts = 3
+	}
+
+	errs := []error{}
+	for attempt := 0; attempt < attempts; attempt++ {
+		if 0 < attempt {
+			delay := policy.delay(attempt)
+			if after, ok := RetryAfter(errs[len(errs)-1]); ok {
+				delay = after
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return join(append(errs, ctx.Err()))
+			case <-timer.C:
+			}
+		} else if nil != ctx.Err() {
+			return join([]error{ctx.Err()})
+		}
+
+		err := fn(ctx)
+		if nil == err {
+			return nil
+		}
+		errs = append(errs, err)
+		if IsPermanent(err) {
+			break
+		}
+	}
+	return join(errs)
+}
@@ -0,0 +1,188 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// temporary is a foreign error reporting itself as temporary, as some net errors do.
+type temporary struct{}
+
+func (temporary) Error() string   { return "temporary" }
+func (temporary) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err                  error
+		retryable, permanent bool
+	}{
+		"marked retryable":         {errors.New("x").WithRetryable(0), true, false},
+		"marked permanent":         {errors.New("x").WithPermanent(), false, true},
+		"outermost mark wins":      {errors.Wrap(errors.New("x").WithRetryable(0), "y").WithPermanent(), false, true},
+		"mark beneath a wrapper":   {fmt.Errorf("wrapped: %w", errors.New("x").WithRetryable(0)), true, false},
+		"Temporary":                {errors.Wrap(temporary{}, "x"), true, false},
+		"Timeout":                  {&net.DNSError{Err: "timeout", IsTimeout: true}, true, false},
+		"KindUnavailable":          {errors.New("x").WithKind(errors.KindUnavailable), true, false},
+		"a child of Unavailable":   {errors.New("x").WithKind(errors.NewKind("test_retry_child", errors.KindUnavailable)), true, false},
+		"context.DeadlineExceeded": {errors.Wrap(context.DeadlineExceeded, "x"), true, false},
+		"context.Canceled":         {errors.Wrap(context.Canceled, "x"), false, true},
+		"in a join":                {errors.Join(sentinel, errors.New("x").WithPermanent()), false, true},
+		"unclassified":             {errors.Wrap(sentinel, "x"), false, false},
+		"nil":                      {nil, false, false},
+	} {
+		if tc.retryable != errors.IsRetryable(tc.err) || tc.permanent != errors.IsPermanent(tc.err) {
+			t.Errorf("%s: IsRetryable = %t, IsPermanent = %t; want %t, %t", name,
+				errors.IsRetryable(tc.err), errors.IsPermanent(tc.err), tc.retryable, tc.permanent)
+		}
+	}
+}
+
+func TestRegisterRetryClassifier(t *testing.T) {
+	type throttled struct{ error }
+	unregister := errors.RegisterRetryClassifier(func(err error) (bool, bool) {
+		_, ok := err.(throttled)
+		return true, ok
+	})
+	t.Cleanup(unregister)
+	if !errors.IsRetryable(errors.Wrap(throttled{sentinel}, "x")) {
+		t.Error("a registered classifier was not consulted")
+	}
+	if errors.IsRetryable(errors.Wrap(sentinel, "x")) {
+		t.Error("a classifier without an opinion decided")
+	}
+
+	unregister()
+	if errors.IsRetryable(errors.Wrap(throttled{sentinel}, "x")) {
+		t.Error("an unregistered classifier was still consulted")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := errors.Wrap(errors.New("x").WithRetryable(3*time.Second), "y")
+	if after, ok := errors.RetryAfter(err); !ok || 3*time.Second != after {
+		t.Errorf("RetryAfter = %s, %t", after, ok)
+	}
+	if _, ok := errors.RetryAfter(errors.New("x").WithRetryable(0)); ok {
+		t.Error("a mark without a delay has a delay")
+	}
+}
+
+// fastPolicy retries without waiting long enough to slow the tests down.
+var fastPolicy = errors.RetryPolicy{Attempts: 4, Initial: time.Millisecond, Multiplier: 2, Jitter: 0.5}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := errors.Retry(context.Background(), fastPolicy, func(context.Context) error {
+		calls++
+		if 3 > calls {
+			return errors.Errorf("attempt %d", calls)
+		}
+		return nil
+	})
+	if nil != err || 3 != calls {
+		t.Errorf("Retry = %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	err = errors.Retry(context.Background(), fastPolicy, func(context.Context) error {
+		calls++
+		return errors.Errorf("attempt %d", calls)
+	})
+	if 4 != calls || nil == err {
+		t.Fatalf("Retry = %v after %d calls, want failure after 4", err, calls)
+	}
+	if want := "attempt 1; attempt 2; attempt 3; attempt 4"; want != err.Error() {
+		t.Errorf("Error() = %q, want every attempt's error", err.Error())
+	}
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestRetry") {
+		t.Errorf("caller is %s, want the call to Retry", errors.Caller(err).Func())
+	}
+}
+
+// retryOnce returns Retry's result as an error, the way a caller usually does.
+func retryOnce(fail bool) error {
+	return errors.Retry(context.Background(), fastPolicy, func(context.Context) error {
+		if fail {
+			return errors.New("failed").WithPermanent()
+		}
+		return nil
+	})
+}
+
+func TestRetryReturnsANilError(t *testing.T) {
+	if err := retryOnce(false); nil != err {
+		t.Errorf("Retry = %#v on success, want a nil error", err)
+	}
+	if err := retryOnce(true); nil == err {
+		t.Error("Retry = nil on failure")
+	} else if _, ok := err.(*errors.E); !ok {
+		t.Errorf("Retry = %T on failure, want an *errors.E", err)
+	}
+}
+
+func TestRetryStopsOnPermanentErrors(t *testing.T) {
+	calls := 0
+	err := errors.Retry(context.Background(), fastPolicy, func(context.Context) error {
+		calls++
+		return errors.New("bad request").WithPermanent()
+	})
+	if 1 != calls || !errors.IsPermanent(err) {
+		t.Errorf("Retry = %v after %d calls, want to stop after 1", err, calls)
+	}
+}
+
+func TestRetryStopsWhenTheContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	policy := errors.RetryPolicy{Attempts: 10, Initial: time.Hour}
+	err := errors.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		cancel()
+		return errors.New("failed")
+	})
+	if 1 != calls || !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry = %v after %d calls, want to stop with the context's error", err, calls)
+	}
+
+	calls = 0
+	err = errors.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return nil
+	})
+	if 0 != calls || !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry = %v after %d calls with a done context", err, calls)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	start := time.Now()
+	calls := 0
+	errors.Retry(context.Background(), errors.RetryPolicy{Attempts: 2, Initial: time.Hour}, func(context.Context) error {
+		calls++
+		return errors.New("throttled").WithRetryable(time.Millisecond)
+	})
+	if 2 != calls || time.Minute < time.Since(start) {
+		t.Errorf("%d calls in %s, want the error's delay used", calls, time.Since(start))
+	}
+}
+
+func TestRetryDelayDoesNotOverflow(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls := 0
+	// The second delay is far beyond the longest time.Duration, and there is no Max to cap it.
+	policy := errors.RetryPolicy{Attempts: 3, Initial: time.Millisecond, Multiplier: 1e300}
+	err := errors.Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return errors.New("failed")
+	})
+	if 2 != calls || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Retry = %v after %d calls, want to wait out the context", err, calls)
+	}
+}
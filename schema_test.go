@@ -0,0 +1,102 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestJSONSchemaV2(t *testing.T) {
+	err := errors.Wrap(fmt.Errorf("fmt layer: %w", errors.New("inner")), "outer").WithCode("c")
+	clr := errors.Caller(err)
+
+	byts, jsonErr := err.MarshalJSONFormat(errors.JSONFormat{Schema: errors.JSONSchemaV2})
+	if nil != jsonErr {
+		t.Fatal(jsonErr)
+	}
+	frames := []map[string]interface{}{}
+	if jsonErr := json.Unmarshal(byts, &frames); nil != jsonErr {
+		t.Fatal(jsonErr)
+	}
+	if 3 != len(frames) {
+		t.Fatalf("frames = %s", byts)
+	}
+
+	outer := frames[0]
+	for member, want := range map[string]interface{}{
+		"schema":   2.0,
+		"index":    0.0,
+		"file":     clr.File(),
+		"base":     path.Base(clr.File()),
+		"line":     float64(clr.Line()),
+		"function": clr.Func(),
+		"package":  "github.com/bdlm/errors/v2_test",
+		"error":    "outer",
+		"code":     "c",
+	} {
+		if want != outer[member] {
+			t.Errorf("%s = %v, want %v", member, outer[member], want)
+		}
+	}
+	if _, ok := outer["caller"]; ok {
+		t.Error("a V2 frame has a caller string")
+	}
+	if _, ok := outer["trace"]; ok {
+		t.Error("a trace was written without being asked for")
+	}
+	if _, ok := frames[1]["file"]; ok || 1.0 != frames[1]["index"] {
+		t.Errorf("a foreign link should carry only its index and message: %v", frames[1])
+	}
+
+	byts, _ = err.MarshalJSONFormat(errors.JSONFormat{Schema: errors.JSONSchemaV2, Trace: true})
+	frames = []map[string]interface{}{}
+	json.Unmarshal(byts, &frames)
+	trace, _ := frames[0]["trace"].([]interface{})
+	if len(clr.Trace()) != len(trace) {
+		t.Fatalf("trace = %v, want %d frames", frames[0]["trace"], len(clr.Trace()))
+	}
+	if top, _ := trace[0].(map[string]interface{}); clr.Func() != top["function"] || float64(clr.Line()) != top["line"] {
+		t.Errorf("the trace does not start at the caller: %v", top)
+	}
+}
+
+func TestSetJSONFormat(t *testing.T) {
+	err := errors.Wrap(errors.New("inner"), "outer")
+	v1, _ := json.Marshal(err)
+	if !strings.Contains(string(v1), `"caller":"#0 `) {
+		t.Fatalf("the default is not JSONSchemaV1: %s", v1)
+	}
+
+	errors.SetJSONFormat(errors.JSONFormat{Schema: errors.JSONSchemaV2})
+	defer errors.SetJSONFormat(errors.JSONFormat{})
+
+	v2, _ := json.Marshal(err)
+	want, _ := err.MarshalJSONFormat(errors.JSONFormat{Schema: errors.JSONSchemaV2})
+	if string(want) != string(v2) {
+		t.Errorf("MarshalJSON = %s, want %s", v2, want)
+	}
+	if verb := fmt.Sprintf("%#+v", err); string(want) != verb {
+		t.Errorf("%%#+v = %s, want %s", verb, want)
+	}
+	if verb := fmt.Sprintf("%#v", err); strings.Contains(verb, `"file"`) {
+		t.Errorf("%%#v has location members: %s", verb)
+	}
+	if perCall, _ := err.MarshalJSONFormat(errors.JSONFormat{Schema: errors.JSONSchemaV1}); string(v1) != string(perCall) {
+		t.Errorf("a per-call format does not override the global one: %s", perCall)
+	}
+
+	decoded, decodeErr := errors.FromJSON(v2)
+	if nil != decodeErr {
+		t.Fatal(decodeErr)
+	}
+	if want, got := fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", decoded); want != got {
+		t.Errorf("a V2 round trip differs:\n got: %s\nwant: %s", got, want)
+	}
+	if errors.Caller(err).File() != errors.Caller(decoded).File() {
+		t.Errorf("file = %s, want the full path %s", errors.Caller(decoded).File(), errors.Caller(err).File())
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Package sentry exports error chains built with github.com/bdlm/errors as Sentry events.
+
+NewEvent converts a chain to the event structure Sentry ingests, and Marshal encodes it, with no
+network access, so the payload can be inspected, queued or tested against a stand-in server. Send
+posts an event to the project a DSN names, through an http.Client the caller supplies:
+
+	if err := sentry.Send(ctx, client, dsn, sentry.NewEvent(err)); nil != err {
+		log.Printf("reporting the error: %v", err)
+	}
+*/
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// maxTagValue is the longest tag value Sentry accepts.
+const maxTagValue = 200
+
+// Event is a Sentry event describing an error.
+type Event struct {
+	EventID   string    `json:"event_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Platform  string    `json:"platform"`
+	Level     string    `json:"level"`
+	// Exception holds one exception per link of the chain.
+	Exception Exceptions `json:"exception"`
+	// Tags are the chain's code, kind, instance ID and fields.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Fingerprint groups the event with the other occurrences of the same failure, see
+	// errors.Fingerprint, rather than leaving the grouping to Sentry's heuristics.
+	Fingerprint []string `json:"fingerprint,omitempty"`
+}
+
+// Exceptions is the exception interface of an event.
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception describes one link of an error chain.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace is the stack trace of an exception.
+type Stacktrace struct {
+	// Frames are ordered oldest call first, as Sentry expects.
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is one call of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// NewEvent returns the event describing err, or nil for a nil error.
+//
+// Each link of the chain is an exception, see errors.Frames, ordered innermost first, so that the
+// outermost -- the error as the application saw it -- is last, where Sentry expects the exception
+// that was raised. An exception's type is the link's code, or failing that its kind, or failing
+// that its Go type; its value is the link's own message; and its stack trace is the link's caller
+// trace, oldest call first, with a frame in the application, see errors.InApp, marked in_app. The
+// branches of an error with several causes are not included.
+//
+// Messages and tags are redacted by the policy set with errors.SetRedactionPolicy.
+func NewEvent(err error) *Event {
+	if nil == err {
+		return nil
+	}
+	event := &Event{
+		EventID:     eventID(),
+		Timestamp:   time.Now().UTC(),
+		Platform:    "go",
+		Level:       "error",
+		Exception:   Exceptions{Values: []Exception{}},
+		Tags:        tags(err),
+		Fingerprint: []string{errors.Fingerprint(err)},
+	}
+	frames, chain := errors.Frames(err), links(err)
+	for i := len(frames) - 1; 0 <= i; i-- {
+		event.Exception.Values = append(event.Exception.Values, exception(chain[i], frames[i]))
+	}
+	return event
+}
+
+// Marshal returns the JSON payload of the event describing err, see NewEvent.
+func Marshal(err error) ([]byte, error) {
+	return json.Marshal(NewEvent(err))
+}
+
+// links returns the links of err's Unwrap chain, outermost first, as errors.Frames has them.
+func links(err error) []error {
+	ret := []error{}
+	for ; nil != err; err = errors.Unwrap(err) {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// exception describes link, whose snapshot is frame.
+func exception(link error, frame errors.Frame) Exception {
+	ex := Exception{
+		Type:  string(frame.Code),
+		Value: errors.RedactMessage(frame.Message),
+	}
+	if "" == ex.Type {
+		ex.Type = frame.Kind
+	}
+	if "" == ex.Type {
+		ex.Type = fmt.Sprintf("%T", link)
+	}
+	clr := errors.Caller(link)
+	if nil == clr {
+		return ex
+	}
+	trace := clr.Trace()
+	if 0 == len(trace) {
+		trace = append(trace, clr)
+	}
+	stack := &Stacktrace{Frames: make([]Frame, 0, len(trace))}
+	for i := len(trace) - 1; 0 <= i; i-- {
+		module, function := splitFunc(trace[i].Func())
+		stack.Frames = append(stack.Frames, Frame{
+			Function: function,
+			Module:   module,
+			Filename: path.Base(trace[i].File()),
+			AbsPath:  trace[i].File(),
+			Lineno:   trace[i].Line(),
+			InApp:    errors.InApp(trace[i].Func(), trace[i].File()),
+		})
+	}
+	ex.Stacktrace = stack
+	return ex
+}
+
+// tags returns the tags of the event describing err.
+func tags(err error) map[string]string {
+	ret := map[string]string{}
+	for k, v := range errors.RedactFields(errors.Fields(err)) {
+		ret[k] = tagValue(fmt.Sprint(v))
+	}
+	if code := errors.CodeOf(err); "" != code {
+		ret["code"] = tagValue(string(code))
+	}
+	if kind := errors.KindOf(err); nil != kind {
+		ret["kind"] = tagValue(kind.Name())
+	}
+	if id := errors.ID(err); "" != id {
+		ret["error_id"] = id
+	}
+	return ret
+}
+
+// tagValue truncates v to the length Sentry accepts, without splitting a character.
+func tagValue(v string) string {
+	if maxTagValue < len(v) {
+		v = strings.ToValidUTF8(v[:maxTagValue], "")
+	}
+	return v
+}
+
+// splitFunc splits a function name as the runtime reports it into its package and the function
+// within it: "github.com/bdlm/errors/v2.(*E).Format" is "github.com/bdlm/errors/v2" and "(*E).Format".
+func splitFunc(fn string) (module, function string) {
+	slash := strings.LastIndex(fn, "/")
+	if i := strings.Index(fn[slash+1:], "."); 0 <= i {
+		return fn[:slash+1+i], fn[slash+2+i:]
+	}
+	return "", fn
+}
+
+// eventID returns a new event ID, a UUID in the 32 hex digit form Sentry uses.
+func eventID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return hex.EncodeToString(id[:])
+}
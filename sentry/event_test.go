@@ -0,0 +1,113 @@
+package sentry_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+	"github.com/bdlm/errors/v2/sentry"
+)
+
+func readConfig() error {
+	return errors.Wrap(io.ErrUnexpectedEOF, "reading the configuration").WithCode("config_unreadable")
+}
+
+func loadConfig() error {
+	return errors.Wrap(readConfig(), "loading the configuration").
+		WithKind(errors.KindInternal).WithFields(map[string]interface{}{"path": "/etc/svc.yaml", "token": "t-1"})
+}
+
+func TestNewEvent(t *testing.T) {
+	err := loadConfig()
+	event := sentry.NewEvent(err)
+
+	if 32 != len(event.EventID) || "go" != event.Platform || "error" != event.Level {
+		t.Errorf("event = %+v", event)
+	}
+	values := event.Exception.Values
+	if 3 != len(values) {
+		t.Fatalf("exceptions = %+v, want one per frame", values)
+	}
+	for i, want := range []sentry.Exception{
+		{Type: "*errors.errorString", Value: "unexpected EOF"},
+		{Type: "config_unreadable", Value: "reading the configuration"},
+		{Type: "internal", Value: "loading the configuration"},
+	} {
+		if want.Type != values[i].Type || want.Value != values[i].Value {
+			t.Errorf("exception %d = %s %q, want %s %q", i, values[i].Type, values[i].Value, want.Type, want.Value)
+		}
+	}
+	if nil != values[0].Stacktrace {
+		t.Error("a foreign error has a stack trace")
+	}
+
+	frames := values[1].Stacktrace.Frames
+	last := frames[len(frames)-1]
+	if "readConfig" != last.Function || "github.com/bdlm/errors/v2/sentry_test" != last.Module ||
+		"event_test.go" != last.Filename || !last.InApp {
+		t.Errorf("the newest frame = %+v, want readConfig, in the application", last)
+	}
+	if "loadConfig" != frames[len(frames)-2].Function {
+		t.Errorf("frames = %+v, want the oldest first", frames)
+	}
+	if first := frames[0]; first.InApp || !strings.HasPrefix(first.Module, "runtime") {
+		t.Errorf("the oldest frame = %+v, want the runtime's, not in the application", first)
+	}
+
+	for key, want := range map[string]string{
+		"code":  "config_unreadable",
+		"kind":  "internal",
+		"path":  "/etc/svc.yaml",
+		"token": errors.Redacted,
+	} {
+		if want != event.Tags[key] {
+			t.Errorf("tag %s = %q, want %q", key, event.Tags[key], want)
+		}
+	}
+	if 1 != len(event.Fingerprint) || errors.Fingerprint(err) != event.Fingerprint[0] {
+		t.Errorf("fingerprint = %v", event.Fingerprint)
+	}
+	if nil != sentry.NewEvent(nil) {
+		t.Error("a nil error has an event")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	byts, err := sentry.Marshal(errors.New("x").WithField("long", strings.Repeat("é", 150)).WithID())
+	if nil != err {
+		t.Fatal(err)
+	}
+	var payload struct {
+		EventID   string            `json:"event_id"`
+		Timestamp string            `json:"timestamp"`
+		Tags      map[string]string `json:"tags"`
+		Exception struct {
+			Values []struct {
+				Type       string `json:"type"`
+				Stacktrace struct {
+					Frames []map[string]interface{} `json:"frames"`
+				} `json:"stacktrace"`
+			} `json:"values"`
+		} `json:"exception"`
+	}
+	if err := json.Unmarshal(byts, &payload); nil != err {
+		t.Fatalf("%s: %v", byts, err)
+	}
+	if 1 != len(payload.Exception.Values) || 0 == len(payload.Exception.Values[0].Stacktrace.Frames) {
+		t.Fatalf("payload = %s", byts)
+	}
+	frame := payload.Exception.Values[0].Stacktrace.Frames[0]
+	for _, member := range []string{"function", "filename", "abs_path", "lineno", "in_app"} {
+		if _, ok := frame[member]; !ok {
+			t.Errorf("a frame has no %s member: %v", member, frame)
+		}
+	}
+	if "" == payload.Timestamp || "" == payload.Tags["error_id"] {
+		t.Errorf("payload = %s", byts)
+	}
+	if long := payload.Tags["long"]; 200 < len(long) || !strings.HasPrefix(strings.Repeat("é", 100), long) {
+		t.Errorf("tag long = %q, want it truncated to 200 bytes", long)
+	}
+}
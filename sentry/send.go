@@ -0,0 +1,95 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	std_http "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// clientName identifies this package to Sentry.
+const clientName = "bdlm-errors/2"
+
+// Send posts event to the Sentry project dsn names, "https://<key>@<host>/<project>", with client,
+// or http.DefaultClient if client is nil. It does nothing for a nil event.
+//
+// A response other than a 2xx status is an error. One of 429 Too Many Requests or a 5xx status is
+// marked retryable, with the delay of its Retry-After header if it has one, see errors.IsRetryable
+// and errors.RetryAfter, so Send can be called from errors.Retry.
+func Send(ctx context.Context, client *std_http.Client, dsn string, event *Event) error {
+	if nil == event {
+		return nil
+	}
+	endpoint, auth, err := parseDSN(dsn)
+	if nil != err {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if nil != err {
+		return errors.Wrap(err, "sentry: encoding the event")
+	}
+	req, err := std_http.NewRequestWithContext(ctx, std_http.MethodPost, endpoint, bytes.NewReader(body))
+	if nil != err {
+		return errors.Wrap(err, "sentry: creating the request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", auth)
+	if nil == client {
+		client = std_http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if nil != err {
+		return errors.Wrap(err, "sentry: sending the event")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if 200 <= resp.StatusCode && 299 >= resp.StatusCode {
+		return nil
+	}
+	failure := errors.Errorf("sentry: sending the event: %s", resp.Status).WithField("status", resp.StatusCode)
+	if std_http.StatusTooManyRequests == resp.StatusCode || 500 <= resp.StatusCode {
+		return failure.WithRetryable(retryAfter(resp.Header.Get("Retry-After")))
+	}
+	return failure.WithPermanent()
+}
+
+// parseDSN returns the store endpoint and the X-Sentry-Auth header of the project dsn names.
+func parseDSN(dsn string) (endpoint, auth string, err error) {
+	u, err := url.Parse(dsn)
+	if nil != err {
+		return "", "", errors.Wrap(err, "sentry: parsing the DSN").WithKind(errors.KindInvalidArgument)
+	}
+	i := strings.LastIndex(u.Path, "/")
+	project := u.Path[i+1:]
+	if nil == u.User || "" == u.User.Username() || "" == u.Host || "" == project || -1 == i {
+		return "", "", errors.New("sentry: the DSN must be of the form https://<key>@<host>/<project>").
+			WithKind(errors.KindInvalidArgument)
+	}
+	endpoint = (&url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path[:i] + "/api/" + project + "/store/",
+	}).String()
+	auth = "Sentry sentry_version=7, sentry_client=" + clientName + ", sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return endpoint, auth, nil
+}
+
+// retryAfter is the delay a Retry-After header asks for, in seconds, or 0 if it asks for none that
+// can be read.
+func retryAfter(header string) time.Duration {
+	if seconds, err := strconv.Atoi(header); nil == err && 0 < seconds {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package sentry_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	std_http "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bdlm/errors/v2"
+	"github.com/bdlm/errors/v2/sentry"
+)
+
+func TestSend(t *testing.T) {
+	var (
+		gotPath, gotAuth string
+		gotEvent         map[string]interface{}
+	)
+	server := httptest.NewServer(std_http.HandlerFunc(func(w std_http.ResponseWriter, r *std_http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotEvent)
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://public-key@", 1) + "/42"
+	event := sentry.NewEvent(errors.New("x"))
+	if err := sentry.Send(context.Background(), server.Client(), dsn, event); nil != err {
+		t.Fatalf("Send = %v", err)
+	}
+	if "/api/42/store/" != gotPath {
+		t.Errorf("path = %q", gotPath)
+	}
+	if !strings.Contains(gotAuth, "sentry_key=public-key") || !strings.Contains(gotAuth, "sentry_version=7") {
+		t.Errorf("X-Sentry-Auth = %q", gotAuth)
+	}
+	if event.EventID != gotEvent["event_id"] {
+		t.Errorf("received %v, want the event", gotEvent)
+	}
+}
+
+func TestSendFailures(t *testing.T) {
+	status := std_http.StatusTooManyRequests
+	server := httptest.NewServer(std_http.HandlerFunc(func(w std_http.ResponseWriter, r *std_http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	dsn := strings.Replace(server.URL, "://", "://key@", 1) + "/1"
+	event := sentry.NewEvent(errors.New("x"))
+
+	err := sentry.Send(context.Background(), server.Client(), dsn, event)
+	if after, _ := errors.RetryAfter(err); !errors.IsRetryable(err) || 30*time.Second != after {
+		t.Errorf("Send = %v, want it retryable after 30s", err)
+	}
+	status = std_http.StatusBadRequest
+	if err := sentry.Send(context.Background(), server.Client(), dsn, event); !errors.IsPermanent(err) {
+		t.Errorf("Send = %v, want it permanent", err)
+	}
+
+	for _, dsn := range []string{"https://sentry.example.com/1", "https://key@sentry.example.com/", "://"} {
+		if err := sentry.Send(context.Background(), nil, dsn, event); !errors.Is(err, errors.KindInvalidArgument) {
+			t.Errorf("Send with DSN %q = %v, want an invalid argument", dsn, err)
+		}
+	}
+	if err := sentry.Send(context.Background(), nil, "://", nil); nil != err {
+		t.Errorf("Send of a nil event = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func validate(skip int) *errors.E {
+	return errors.NewSkip(skip, "invalid")
+}
+
+func wrapInvalid(skip int, err error) *errors.E {
+	return errors.WrapSkip(skip, err, "invalid %s", "input")
+}
+
+func TestNewSkip(t *testing.T) {
+	if err := validate(0); !strings.HasSuffix(errors.Caller(err).Func(), ".validate") {
+		t.Errorf("skip 0: caller is %s, want the helper", errors.Caller(err).Func())
+	}
+	err := validate(1)
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestNewSkip") {
+		t.Errorf("skip 1: caller is %s, want the helper's caller", errors.Caller(err).Func())
+	}
+	if "invalid" != err.Error() {
+		t.Errorf("message = %q", err.Error())
+	}
+}
+
+func TestWrapSkip(t *testing.T) {
+	err := wrapInvalid(1, sentinel)
+	if !strings.HasSuffix(errors.Caller(err).Func(), ".TestWrapSkip") {
+		t.Errorf("caller is %s, want the helper's caller", errors.Caller(err).Func())
+	}
+	if !errors.Is(err, sentinel) {
+		t.Error("the wrapped error is not on the chain")
+	}
+	if !strings.HasPrefix(err.Error(), "invalid input") {
+		t.Errorf("message = %q, want the formatted message first", err.Error())
+	}
+}
+
+func markedNew() *errors.E {
+	errors.Helper()
+	return errors.New("from a helper")
+}
+
+func markedOuter() *errors.E {
+	errors.Helper()
+	return markedNew()
+}
+
+func markedSkip() *errors.E {
+	errors.Helper()
+	return errors.NewSkip(1, "from a helper")
+}
+
+func markedCallback(fn func() *errors.E) *errors.E {
+	errors.Helper()
+	return fn()
+}
+
+func TestHelper(t *testing.T) {
+	for name, err := range map[string]*errors.E{
+		"helper":              markedNew(),
+		"helper of a helper":  markedOuter(),
+		"helper with a skip":  markedSkip(),
+		"helper with Wrap":    func() *errors.E { errors.Helper(); return errors.Wrap(sentinel, "x") }(),
+		"helper with Errorf":  func() *errors.E { errors.Helper(); return errors.Errorf("x") }(),
+		"helper with Track":   func() *errors.E { errors.Helper(); return errors.Track(sentinel) }(),
+		"helper with NewSkip": func() *errors.E { errors.Helper(); return errors.NewSkip(0, "x") }(),
+	} {
+		clr := errors.Caller(err)
+		if !strings.HasSuffix(clr.Func(), ".TestHelper") {
+			t.Errorf("%s: caller is %s, want the test", name, clr.Func())
+		}
+		if trace := clr.Trace(); 0 == len(trace) || trace[0].Func() != clr.Func() {
+			t.Errorf("%s: the trace does not start at the caller: %v", name, funcs(trace))
+		}
+	}
+
+	// Further down a trace a helper's frames are kept.
+	trace := funcs(errors.Caller(markedCallback(func() *errors.E { return errors.New("x") })).Trace())
+	if 2 > len(trace) || !strings.HasSuffix(trace[1], ".markedCallback") {
+		t.Errorf("trace = %v, want the helper kept below the origin", trace)
+	}
+}
@@ -0,0 +1,94 @@
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer, so an *E logged with slog.Any is a group carrying its caller
+// data rather than the flat Error() string. The group's members are those of LogAttrs.
+func (e *E) LogValue() slog.Value {
+	if nil == e {
+		return slog.AnyValue(nil)
+	}
+	return slog.GroupValue(LogAttrs(e)...)
+}
+
+// LogAttrs returns the slog attributes describing err, for any error type:
+//
+//	msg     err.Error()
+//	type    the Go type of err, when it is not an *E
+//	code    the nearest code in the chain, see CodeOf
+//	kind    the name of the nearest kind in the chain, see KindOf
+//	id      the outermost instance ID in the chain, see ID
+//	fields  the fields of the whole chain as a group, see Fields
+//	frames  one object per link of the chain, outermost first, as JSONSchemaV2 writes them
+//
+// Messages and fields are redacted by the policy set with SetRedactionPolicy. Members without a
+// value are left out. An *E anywhere in the chain contributes its caller data
+// and structured context even when the outermost error is foreign -- a fmt.Errorf("%w") wrapper,
+// say -- which is what slog's own handling of an error value, err.Error(), discards. It returns nil
+// for a nil error.
+func LogAttrs(err error) []slog.Attr {
+	if nil == err {
+		return nil
+	}
+	attrs := []slog.Attr{slog.String("msg", RedactMessage(err.Error()))}
+	if _, ok := err.(*E); !ok {
+		attrs = append(attrs, slog.String("type", fmt.Sprintf("%T", err)))
+	}
+	if code := CodeOf(err); "" != code {
+		attrs = append(attrs, slog.String("code", string(code)))
+	}
+	if kind := KindOf(err); nil != kind {
+		attrs = append(attrs, slog.String("kind", kind.Name()))
+	}
+	if id := ID(err); "" != id {
+		attrs = append(attrs, slog.String("id", id))
+	}
+	if fields := RedactFields(Fields(err)); 0 < len(fields) {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		group := make([]interface{}, 0, len(fields))
+		for _, k := range keys {
+			group = append(group, slog.Any(k, fields[k]))
+		}
+		attrs = append(attrs, slog.Group("fields", group...))
+	}
+	frames := []map[string]interface{}{}
+	for key, link := 0, err; nil != link; key, link = key+1, Unwrap(link) {
+		frames = append(frames, frameJSON(key, link, true, JSONFormat{Schema: JSONSchemaV2}))
+	}
+	return append(attrs, slog.Any("frames", frames))
+}
+
+// LogValue implements slog.LogValuer, so a Secret logged directly is redacted too.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// ReplaceAttr is a slog.HandlerOptions.ReplaceAttr function that expands any error value into the
+// group LogAttrs describes. An *E needs no help, since it is a slog.LogValuer; this covers the
+// foreign errors logged alongside it:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+//		ReplaceAttr: errors.ReplaceAttr,
+//	}))
+//
+// Every other attribute is returned unchanged.
+func ReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if slog.KindAny != attr.Value.Kind() {
+		return attr
+	}
+	if err, ok := attr.Value.Any().(error); ok && nil != err {
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(LogAttrs(err)...)}
+	}
+	return attr
+}
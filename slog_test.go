@@ -0,0 +1,81 @@
+//go:build go1.21
+// +build go1.21
+
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// logJSON logs err as "err" through a JSON handler and returns the decoded value of that member.
+func logJSON(t *testing.T, err error, opts *slog.HandlerOptions) map[string]interface{} {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, opts)).Error("failed", slog.Any("err", err))
+	record := map[string]interface{}{}
+	if jsonErr := json.Unmarshal(buf.Bytes(), &record); nil != jsonErr {
+		t.Fatalf("%v: %s", jsonErr, buf.Bytes())
+	}
+	group, ok := record["err"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("err is not a group: %s", buf.Bytes())
+	}
+	return group
+}
+
+func TestLogValue(t *testing.T) {
+	err := errors.Wrap(errors.New("inner").WithCode("c").WithKind(errors.KindNotFound), "outer").
+		WithField("user", "alice")
+
+	group := logJSON(t, err, nil)
+	if "outer: inner" != group["msg"] || "c" != group["code"] || "not_found" != group["kind"] {
+		t.Errorf("group = %v", group)
+	}
+	if _, ok := group["type"]; ok {
+		t.Error("an *E should not report its type")
+	}
+	if fields, _ := group["fields"].(map[string]interface{}); "alice" != fields["user"] {
+		t.Errorf("fields = %v", group["fields"])
+	}
+	frames, _ := group["frames"].([]interface{})
+	if 2 != len(frames) {
+		t.Fatalf("frames = %v", group["frames"])
+	}
+	outer, _ := frames[0].(map[string]interface{})
+	if errors.Caller(err).Func() != outer["function"] || float64(errors.Caller(err).Line()) != outer["line"] {
+		t.Errorf("outer frame = %v, want the caller %s", outer, errors.Caller(err))
+	}
+}
+
+func TestReplaceAttrExpandsForeignErrors(t *testing.T) {
+	inner := errors.New("inner").WithField("user", "alice")
+	err := fmt.Errorf("fmt layer: %w", inner)
+
+	group := logJSON(t, err, &slog.HandlerOptions{ReplaceAttr: errors.ReplaceAttr})
+	if "fmt layer: inner" != group["msg"] || "*fmt.wrapError" != group["type"] {
+		t.Errorf("group = %v", group)
+	}
+	if fields, _ := group["fields"].(map[string]interface{}); "alice" != fields["user"] {
+		t.Errorf("the fields of an *E inside a foreign error were lost: %v", group["fields"])
+	}
+	frames, _ := group["frames"].([]interface{})
+	if 2 != len(frames) {
+		t.Fatalf("frames = %v", group["frames"])
+	}
+	if frame, _ := frames[1].(map[string]interface{}); errors.Caller(inner).Func() != frame["function"] {
+		t.Errorf("the caller of an *E inside a foreign error was lost: %v", frame)
+	}
+
+	if attr := errors.ReplaceAttr(nil, slog.Int("n", 1)); 1 != attr.Value.Int64() {
+		t.Errorf("a non-error attribute was changed: %v", attr)
+	}
+	if nil != errors.LogAttrs(nil) {
+		t.Error("a nil error has attributes")
+	}
+}
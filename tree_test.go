@@ -0,0 +1,107 @@
+package errors_test
+
+import (
+	"encoding/json"
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+// treeErr is a chain that passes through a multi-%w fmt.Errorf, which has a message of its own, and
+// an errors.Join, which does not.
+func treeErr() (top, x, mid, deep *errors.E) {
+	x = errors.New("x")
+	deep = errors.New("deep")
+	mid = errors.Wrap(deep, "mid")
+	top = errors.Wrap(fmt.Errorf("multi: %w and %w", x, std_errors.Join(mid, errOther)), "top")
+	return top, x, mid, deep
+}
+
+func TestFormatRendersTheErrorTree(t *testing.T) {
+	top, x, mid, deep := treeErr()
+	at := func(err *errors.E) string {
+		return fmt.Sprintf("tree_test.go:%d (%s);", errors.Caller(err).Line(), errors.Caller(err).Func())
+	}
+
+	want := strings.Join([]string{
+		"top - #0 " + at(top),
+		"multi: x and mid: deep\n" + errOther.Error() + " - #1 n/a",
+		"    x - #1.0.0 " + at(x),
+		"    #1.1.0 n/a",
+		"        mid - #1.1.0.0.0 " + at(mid),
+		"        deep - #1.1.0.0.1 " + at(deep),
+		"        " + errOther.Error() + " - #1.1.0.1.0 n/a",
+	}, "\n")
+	if got := fmt.Sprintf("% +v", top); want != got {
+		t.Errorf("%% +v:\n got: %s\nwant: %s", got, want)
+	}
+
+	got := fmt.Sprintf("%+v", top)
+	for _, frame := range []string{"x - #1.0.0 " + at(x), "mid - #1.1.0.0.0 " + at(mid), "deep - #1.1.0.0.1 " + at(deep)} {
+		if !strings.Contains(got, frame) {
+			t.Errorf("%%+v = %q, want it to contain %q", got, frame)
+		}
+	}
+	if strings.Contains(got, "\n    ") {
+		t.Errorf("%%+v is indented: %q", got)
+	}
+}
+
+func TestMarshalJSONNestsCauses(t *testing.T) {
+	top, x, _, deep := treeErr()
+
+	byts, err := json.Marshal(top)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%#+v", top); string(byts) != got {
+		t.Errorf("%%#+v and MarshalJSON differ:\n%s\n%s", got, byts)
+	}
+
+	type frame struct {
+		Caller string    `json:"caller"`
+		Error  string    `json:"error"`
+		Causes [][]frame `json:"causes"`
+	}
+	frames := []frame{}
+	if err := json.Unmarshal(byts, &frames); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(frames) || 2 != len(frames[1].Causes) {
+		t.Fatalf("frames = %s", byts)
+	}
+	if branch := frames[1].Causes[0]; 1 != len(branch) || "x" != branch[0].Error ||
+		!strings.Contains(branch[0].Caller, fmt.Sprintf(":%d ", errors.Caller(x).Line())) {
+		t.Errorf("first branch = %+v", branch)
+	}
+	join := frames[1].Causes[1]
+	if 1 != len(join) || "" != join[0].Error || 2 != len(join[0].Causes) {
+		t.Fatalf("an errors.Join should be a frame without a message of its own: %+v", join)
+	}
+	if chain := join[0].Causes[0]; 2 != len(chain) || "deep" != chain[1].Error ||
+		!strings.Contains(chain[1].Caller, fmt.Sprintf(":%d ", errors.Caller(deep).Line())) {
+		t.Errorf("nested branch = %+v", chain)
+	}
+}
+
+func TestFromJSONRebuildsTheErrorTree(t *testing.T) {
+	top, _, _, _ := treeErr()
+	byts, _ := json.Marshal(top)
+	decoded, err := errors.FromJSON(byts)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if top.Error() != decoded.Error() {
+		t.Errorf("Error() = %q, want %q", decoded.Error(), top.Error())
+	}
+	if want, got := fmt.Sprintf("% +v", top), fmt.Sprintf("% +v", decoded); want != got {
+		t.Errorf("%% +v differs:\n got: %s\nwant: %s", got, want)
+	}
+	var remote interface{ Unwrap() []error }
+	if !errors.As(decoded, &remote) {
+		t.Error("the decoded branches cannot be unwrapped")
+	}
+}
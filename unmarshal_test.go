@@ -0,0 +1,88 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bdlm/errors/v2"
+)
+
+func TestUnmarshalJSONRoundTrip(t *testing.T) {
+	original := errors.Trace(errors.Wrap(
+		fmt.Errorf("fmt layer: %w", errors.New("inner").WithCode("c").WithKind(errors.KindNotFound)),
+		"outer",
+	).WithField("user", "alice"))
+
+	byts, err := json.Marshal(original)
+	if nil != err {
+		t.Fatal(err)
+	}
+	decoded := &errors.E{}
+	if err := json.Unmarshal(byts, decoded); nil != err {
+		t.Fatal(err)
+	}
+
+	if original.Error() != decoded.Error() {
+		t.Errorf("Error() = %q, want %q", decoded.Error(), original.Error())
+	}
+	if want, got := fmt.Sprintf("%+v", original), fmt.Sprintf("%+v", decoded); want != got {
+		t.Errorf("%%+v differs:\n got: %s\nwant: %s", got, want)
+	}
+	if errors.Caller(original).Func() != errors.Caller(decoded).Func() ||
+		errors.Caller(original).Line() != errors.Caller(decoded).Line() {
+		t.Errorf("caller = %s, want %s", errors.Caller(decoded), errors.Caller(original))
+	}
+	if "alice" != errors.Fields(decoded)["user"] {
+		t.Errorf("fields = %v", errors.Fields(decoded))
+	}
+	if "c" != errors.CodeOf(decoded) || !errors.Is(decoded, errors.KindNotFound) {
+		t.Error("the code or kind was lost")
+	}
+	if !errors.IsRemote(decoded) {
+		t.Error("decoded frames should be marked as remote")
+	}
+
+	// A rebuilt chain has a fingerprint of its own, see Fingerprint.
+	again, _ := json.Marshal(decoded)
+	got := strings.Replace(string(again), errors.Fingerprint(decoded), errors.Fingerprint(original), 1)
+	if got = strings.Replace(got, `,"remote":true`, "", -1); string(byts) != got {
+		t.Errorf("re-encoding differs:\n got: %s\nwant: %s", got, byts)
+	}
+}
+
+func TestFromJSONAcceptsFlatCallerStrings(t *testing.T) {
+	decoded, err := errors.FromJSON([]byte(`[
+		{"caller":"#0 handler.go:40 (example.com/svc.(*Server).Handle)","error":"request failed"},
+		{"caller":"#1 n/a","error":"EOF"}
+	]`))
+	if nil != err {
+		t.Fatal(err)
+	}
+	clr := errors.Caller(decoded)
+	if "handler.go" != clr.File() || 40 != clr.Line() || "example.com/svc.(*Server).Handle" != clr.Func() {
+		t.Errorf("caller = %s:%d (%s)", clr.File(), clr.Line(), clr.Func())
+	}
+	if "request failed: EOF" != decoded.Error() {
+		t.Errorf("Error() = %q", decoded.Error())
+	}
+	if nil != errors.Caller(errors.Unwrap(decoded)) {
+		t.Error(`"n/a" should decode to no caller data`)
+	}
+
+	single, err := errors.FromJSON([]byte(`{"caller":"#0 main.go:7 (main.main)","error":"single"}`))
+	if nil != err || "single" != single.Error() {
+		t.Errorf("a single object: %v, %v", single, err)
+	}
+	for _, empty := range []string{"null", "[]"} {
+		if decoded, err := errors.FromJSON([]byte(empty)); nil != decoded || nil != err {
+			t.Errorf("%s: %v, %v", empty, decoded, err)
+		}
+	}
+	for _, bad := range []string{`[{"caller":"somewhere"}]`, `{"error":`, `"a string"`} {
+		if _, err := errors.FromJSON([]byte(bad)); nil == err {
+			t.Errorf("%s: no error", bad)
+		}
+	}
+}